@@ -0,0 +1,304 @@
+package config_mode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"design-pattern-go/book-learn/p1-singleton-pattern/singleton"
+)
+
+/**
+单例模式-可热更新的配置
+配置从 JSON 文件和环境变量加载，轮询文件的修改时间，
+变化后校验新配置，校验通过再整体替换，读到的永远是完整的一份配置
+*/
+
+// DbConfig 数据库配置，拿到的快照只读，不要修改
+type DbConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	User string `json:"user"`
+	Pwd  string `json:"pwd"`
+}
+
+// Validate 校验配置
+func (c *DbConfig) Validate() error {
+	if strings.TrimSpace(c.Host) == "" {
+		return fmt.Errorf("invalid Host is %s", c.Host)
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid Port is %d", c.Port)
+	}
+	if strings.TrimSpace(c.User) == "" {
+		return fmt.Errorf("invalid User is %s", c.User)
+	}
+	return nil
+}
+
+// Option 设置 Store 的参数
+type Option func(s *Store)
+
+// WithEnvPrefix 环境变量前缀，默认 "DB_"，即 DB_HOST、DB_PORT、DB_USER、DB_PWD
+func WithEnvPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.envPrefix = prefix
+	}
+}
+
+// WithLookupEnv 替换读取环境变量的函数，默认 os.LookupEnv
+func WithLookupEnv(lookup func(string) (string, bool)) Option {
+	return func(s *Store) {
+		s.lookupEnv = lookup
+	}
+}
+
+// WithPollInterval 轮询文件的间隔，<=0 表示不监听文件变化
+func WithPollInterval(d time.Duration) Option {
+	return func(s *Store) {
+		s.interval = d
+	}
+}
+
+// Store 持有当前配置快照
+type Store struct {
+	path      string
+	envPrefix string
+	lookupEnv func(string) (string, bool)
+	interval  time.Duration
+
+	current atomic.Value // *DbConfig
+
+	reloadMu    sync.Mutex // 串行化 Reload 和通知
+	mu          sync.Mutex // 保护下面的字段
+	modTime     time.Time
+	size        int64
+	lastErr     error
+	subscribers map[int]func(old, new *DbConfig)
+	nextID      int
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+// NewStore 加载 path 对应的配置，配置不合法时返回错误
+func NewStore(path string, opts ...Option) (*Store, error) {
+	s := &Store{
+		path:        path,
+		envPrefix:   "DB_",
+		lookupEnv:   os.LookupEnv,
+		interval:    2 * time.Second,
+		subscribers: make(map[int]func(old, new *DbConfig)),
+		stop:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	if s.interval > 0 {
+		go s.watch()
+	} else {
+		close(s.stopped)
+	}
+	return s, nil
+}
+
+// Get 当前配置快照
+func (s *Store) Get() *DbConfig {
+	return s.current.Load().(*DbConfig)
+}
+
+// LastError 最近一次重新加载失败的错误，成功后为 nil
+func (s *Store) LastError() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
+
+// Subscribe 配置变化时回调 fn，返回取消订阅的函数。
+// 回调在加载配置的 goroutine 中串行执行，回调里不能再调用 Reload
+func (s *Store) Subscribe(fn func(old, new *DbConfig)) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = fn
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subscribers, id)
+	}
+}
+
+// Reload 重新读取文件和环境变量，配置合法且有变化时替换并通知订阅者。
+// 返回配置是否发生了变化；失败时保留原来的配置
+func (s *Store) Reload() (bool, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err == nil {
+		var cfg *DbConfig
+		if cfg, err = s.load(); err == nil {
+			return s.swap(info, cfg), nil
+		}
+	}
+	s.mu.Lock()
+	s.lastErr = err
+	s.mu.Unlock()
+	return false, err
+}
+
+// swap 替换配置快照并通知订阅者，调用方需要持有 s.reloadMu
+func (s *Store) swap(info os.FileInfo, cfg *DbConfig) bool {
+	s.mu.Lock()
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	s.lastErr = nil
+	fns := s.subscriberList()
+	s.mu.Unlock()
+
+	old, _ := s.current.Load().(*DbConfig)
+	if old != nil && *old == *cfg {
+		return false
+	}
+	s.current.Store(cfg)
+	if old != nil {
+		for _, fn := range fns {
+			fn(old, cfg)
+		}
+	}
+	return true
+}
+
+// subscriberList 按订阅顺序返回订阅者，调用方需要持有 s.mu
+func (s *Store) subscriberList() []func(old, new *DbConfig) {
+	fns := make([]func(old, new *DbConfig), 0, len(s.subscribers))
+	for id := 0; id < s.nextID; id++ {
+		if fn, ok := s.subscribers[id]; ok {
+			fns = append(fns, fn)
+		}
+	}
+	return fns
+}
+
+// load 读取文件，再用环境变量覆盖，最后校验
+func (s *Store) load() (*DbConfig, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &DbConfig{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", s.path, err)
+	}
+
+	if v, ok := s.lookupEnv(s.envPrefix + "HOST"); ok {
+		cfg.Host = v
+	}
+	if v, ok := s.lookupEnv(s.envPrefix + "PORT"); ok {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %sPORT is %s", s.envPrefix, v)
+		}
+		cfg.Port = port
+	}
+	if v, ok := s.lookupEnv(s.envPrefix + "USER"); ok {
+		cfg.User = v
+	}
+	if v, ok := s.lookupEnv(s.envPrefix + "PWD"); ok {
+		cfg.Pwd = v
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// changed 文件的修改时间或大小是否变化
+func (s *Store) changed() bool {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !info.ModTime().Equal(s.modTime) || info.Size() != s.size
+}
+
+func (s *Store) watch() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if s.changed() {
+				_, _ = s.Reload()
+			}
+		}
+	}
+}
+
+// Close 停止监听文件
+func (s *Store) Close(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	select {
+	case <-s.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ConfigPath 配置文件路径，取环境变量 DB_CONFIG，默认 config.json
+func ConfigPath() string {
+	if path, ok := os.LookupEnv("DB_CONFIG"); ok {
+		return path
+	}
+	return "config.json"
+}
+
+// 配置文件缺失或不合法时不能把错误永久缓存，用 Retry 退避后重新加载
+var store = singleton.NewRetry(func() (*Store, error) {
+	s, err := NewStore(ConfigPath())
+	if err != nil {
+		return nil, err
+	}
+	if err := singleton.RegisterCloser("config_mode.Store", s.Close); err != nil {
+		return nil, err
+	}
+	return s, nil
+})
+
+// GetStore 获取配置单例，加载失败后处于退避期时返回 *singleton.RetryError
+func GetStore() (*Store, error) {
+	return store.Get()
+}
+
+// Current 当前配置快照
+func Current() (*DbConfig, error) {
+	s, err := store.Get()
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(), nil
+}
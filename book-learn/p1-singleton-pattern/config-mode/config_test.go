@@ -0,0 +1,182 @@
+package config_mode
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"design-pattern-go/book-learn/p1-singleton-pattern/singleton"
+)
+
+func noEnv(string) (string, bool) { return "", false }
+
+func writeConfig(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestStore(t *testing.T, data string, opts ...Option) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, data)
+	s, err := NewStore(path, append([]Option{WithLookupEnv(noEnv), WithPollInterval(0)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close(context.Background()) })
+	return s, path
+}
+
+func TestReload(t *testing.T) {
+	s, path := newTestStore(t, `{"host":"db1","port":3306,"user":"root"}`)
+	first := s.Get()
+
+	type change struct{ old, new *DbConfig }
+	var changes []change
+	unsubscribe := s.Subscribe(func(old, new *DbConfig) {
+		changes = append(changes, change{old, new})
+	})
+
+	// 内容不变不算变化，不通知
+	if changed, err := s.Reload(); err != nil || changed {
+		t.Fatalf("Reload = %v, %v, want no change", changed, err)
+	}
+
+	writeConfig(t, path, `{"host":"db2","port":3307,"user":"root"}`)
+	if changed, err := s.Reload(); err != nil || !changed {
+		t.Fatalf("Reload = %v, %v, want change", changed, err)
+	}
+	if got := s.Get(); got.Host != "db2" || got.Port != 3307 {
+		t.Fatalf("Get = %+v", got)
+	}
+	if len(changes) != 1 || changes[0].old != first || changes[0].new != s.Get() {
+		t.Fatalf("subscriber got %+v", changes)
+	}
+	if *first != (DbConfig{Host: "db1", Port: 3306, User: "root"}) {
+		t.Fatal("old snapshot was modified")
+	}
+
+	unsubscribe()
+	writeConfig(t, path, `{"host":"db3","port":3307,"user":"root"}`)
+	if _, err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatal("unsubscribed callback was called")
+	}
+}
+
+func TestReloadInvalidKeepsOld(t *testing.T) {
+	s, path := newTestStore(t, `{"host":"db1","port":3306,"user":"root"}`)
+	old := s.Get()
+	notified := false
+	s.Subscribe(func(_, _ *DbConfig) { notified = true })
+
+	for _, data := range []string{
+		`{"host":"db2","port":70000,"user":"root"}`, // 校验失败
+		`{"host":"db2",`, // JSON 不完整
+	} {
+		writeConfig(t, path, data)
+		if _, err := s.Reload(); err == nil {
+			t.Fatalf("Reload of %s succeeded", data)
+		}
+		if s.Get() != old {
+			t.Fatalf("invalid config %s replaced the snapshot", data)
+		}
+		if s.LastError() == nil {
+			t.Fatal("LastError not set")
+		}
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Reload(); !errors.Is(err, os.ErrNotExist) || s.Get() != old {
+		t.Fatalf("Reload of missing file err = %v", err)
+	}
+	if notified {
+		t.Fatal("subscriber notified of an invalid config")
+	}
+
+	// 修好之后恢复
+	writeConfig(t, path, `{"host":"db2","port":3306,"user":"root"}`)
+	if changed, err := s.Reload(); err != nil || !changed {
+		t.Fatalf("Reload = %v, %v", changed, err)
+	}
+	if s.LastError() != nil || s.Get().Host != "db2" || !notified {
+		t.Fatalf("did not recover: %+v, %v", s.Get(), s.LastError())
+	}
+}
+
+func TestEnvOverride(t *testing.T) {
+	env := map[string]string{"APP_HOST": "env-host", "APP_PORT": "5432"}
+	s, _ := newTestStore(t, `{"host":"db1","port":3306,"user":"root"}`,
+		WithEnvPrefix("APP_"),
+		WithLookupEnv(func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		}))
+	if got := s.Get(); got.Host != "env-host" || got.Port != 5432 || got.User != "root" {
+		t.Fatalf("Get = %+v", got)
+	}
+	env["APP_PORT"] = "abc"
+	if _, err := s.Reload(); err == nil || s.Get().Port != 5432 {
+		t.Fatalf("invalid env port accepted: %v", err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, `{"host":"db1","port":3306,"user":"root"}`)
+	s, err := NewStore(path, WithLookupEnv(noEnv), WithPollInterval(5*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan *DbConfig, 1)
+	s.Subscribe(func(_, new *DbConfig) { changed <- new })
+
+	// 大小也变了，不依赖文件系统修改时间的精度
+	writeConfig(t, path, `{"host":"db-two","port":3306,"user":"root"}`)
+	select {
+	case cfg := <-changed:
+		if cfg.Host != "db-two" {
+			t.Fatalf("got %+v", cfg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("watcher did not reload")
+	}
+	if err := s.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestGetStoreRecovers 第一次访问时配置文件不存在，文件出现后可以重新加载
+func TestGetStoreRecovers(t *testing.T) {
+	if store.Initialized() {
+		t.Skip("store already loaded by an earlier run")
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv("DB_CONFIG", path)
+	if _, err := GetStore(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("err = %v, want os.ErrNotExist", err)
+	}
+	var retryErr *singleton.RetryError
+	if _, err := GetStore(); !errors.As(err, &retryErr) {
+		t.Fatalf("err during backoff = %v, want *singleton.RetryError", err)
+	}
+
+	writeConfig(t, path, `{"host":"db1","port":3306,"user":"root"}`)
+	time.Sleep(singleton.DefaultBackoff.Delay(1) * 2)
+	s, err := GetStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close(context.Background()) })
+	if cfg, err := Current(); err != nil || cfg.Host != "db1" {
+		t.Fatalf("Current = %+v, %v", cfg, err)
+	}
+}
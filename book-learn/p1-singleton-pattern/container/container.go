@@ -0,0 +1,389 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+/**
+依赖注入容器
+用包级别的全局变量做单例，依赖关系藏在各个 GetInstance 里，
+容器按类型注册构造函数，自动解析依赖，并统一管理实例的生命周期
+*/
+
+// Lifetime 实例的生命周期
+type Lifetime int
+
+const (
+	Singleton Lifetime = iota // 整个容器只创建一次
+	Transient                 // 每次解析都创建新的实例
+	Scoped                    // 每个 Scope（比如一次请求）创建一次
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Transient:
+		return "transient"
+	case Scoped:
+		return "scoped"
+	default:
+		return fmt.Sprintf("Lifetime(%d)", int(l))
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+var (
+	ErrNotFunc       = errors.New("container: not a function")
+	ErrScopeRequired = errors.New("container: scoped type resolved outside a scope")
+	// ErrCaptiveDependency 单例直接或经过 Transient 依赖了 Scoped 类型，
+	// 单例会把某个作用域的实例永久留下
+	ErrCaptiveDependency = errors.New("container: singleton depends on a scoped type")
+)
+
+// CycleError 依赖形成了环
+type CycleError struct {
+	Path []reflect.Type
+}
+
+func (e *CycleError) Error() string {
+	names := make([]string, 0, len(e.Path))
+	for _, t := range e.Path {
+		names = append(names, t.String())
+	}
+	return "container: dependency cycle: " + strings.Join(names, " -> ")
+}
+
+// MissingError 依赖的类型没有注册
+type MissingError struct {
+	Type     reflect.Type
+	Required reflect.Type // 需要它的类型，直接 Invoke 时为 nil
+}
+
+func (e *MissingError) Error() string {
+	if e.Required == nil {
+		return fmt.Sprintf("container: no provider for %s", e.Type)
+	}
+	return fmt.Sprintf("container: no provider for %s (required by %s)", e.Type, e.Required)
+}
+
+// ProvideOption 设置注册参数
+type ProvideOption func(p *provider)
+
+// WithLifetime 设置生命周期，默认 Singleton
+func WithLifetime(lifetime Lifetime) ProvideOption {
+	return func(p *provider) {
+		p.lifetime = lifetime
+	}
+}
+
+// AsTransient 等同于 WithLifetime(Transient)
+func AsTransient() ProvideOption {
+	return WithLifetime(Transient)
+}
+
+// AsScoped 等同于 WithLifetime(Scoped)
+func AsScoped() ProvideOption {
+	return WithLifetime(Scoped)
+}
+
+type provider struct {
+	ctor     reflect.Value
+	out      reflect.Type
+	deps     []reflect.Type
+	hasErr   bool
+	lifetime Lifetime
+
+	cell instanceCell // Singleton 的实例
+}
+
+// instanceCell 缓存一个实例，构造失败不缓存
+type instanceCell struct {
+	mu   sync.Mutex
+	done bool
+	val  reflect.Value
+}
+
+// Container 依赖注入容器
+type Container struct {
+	mu        sync.RWMutex
+	providers map[reflect.Type]*provider
+	order     []reflect.Type
+}
+
+// New 创建容器
+func New() *Container {
+	return &Container{
+		providers: make(map[reflect.Type]*provider),
+	}
+}
+
+// Provide 注册构造函数。构造函数的参数是它的依赖，
+// 返回值为 T 或 (T, error)，T 就是注册的类型
+func (c *Container) Provide(constructor any, opts ...ProvideOption) error {
+	ctor := reflect.ValueOf(constructor)
+	if ctor.Kind() != reflect.Func {
+		return fmt.Errorf("%w: %T", ErrNotFunc, constructor)
+	}
+	ft := ctor.Type()
+	switch {
+	case ft.NumOut() == 1 && ft.Out(0) != errorType:
+	case ft.NumOut() == 2 && ft.Out(1) == errorType:
+	default:
+		return fmt.Errorf("container: constructor %s must return T or (T, error)", ft)
+	}
+
+	p := &provider{
+		ctor:     ctor,
+		out:      ft.Out(0),
+		hasErr:   ft.NumOut() == 2,
+		lifetime: Singleton,
+	}
+	for i := 0; i < ft.NumIn(); i++ {
+		p.deps = append(p.deps, ft.In(i))
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.providers[p.out]; ok {
+		return fmt.Errorf("container: %s already provided", p.out)
+	}
+	c.providers[p.out] = p
+	c.order = append(c.order, p.out)
+	return nil
+}
+
+// MustProvide 注册失败时 panic
+func (c *Container) MustProvide(constructor any, opts ...ProvideOption) {
+	if err := c.Provide(constructor, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// Invoke 解析 fn 的参数并调用 fn；fn 最后一个返回值为 error 时返回该错误
+func (c *Container) Invoke(fn any) error {
+	return c.invoke(nil, fn)
+}
+
+// Validate 检查所有注册的类型是否存在缺失的依赖、循环依赖或单例依赖 Scoped 类型，不会创建实例
+func (c *Container) Validate() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, t := range c.order {
+		if err := c.check(t, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scope 创建一个作用域，Scoped 类型的实例在同一个作用域内共享
+func (c *Container) Scope() *Scope {
+	return &Scope{
+		c:         c,
+		instances: make(map[reflect.Type]*instanceCell),
+	}
+}
+
+// Resolve 按类型取出实例
+func Resolve[T any](c *Container) (T, error) {
+	return resolveAs[T](c, nil)
+}
+
+func resolveAs[T any](c *Container, s *Scope) (T, error) {
+	var val T
+	t := reflect.TypeOf(&val).Elem()
+	if err := c.checkRoot(t); err != nil {
+		return val, err
+	}
+	v, err := c.resolve(t, s)
+	if err != nil {
+		return val, err
+	}
+	return v.Interface().(T), nil
+}
+
+func (c *Container) invoke(s *Scope, fn any) error {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func {
+		return fmt.Errorf("%w: %T", ErrNotFunc, fn)
+	}
+	ft := fv.Type()
+
+	args := make([]reflect.Value, ft.NumIn())
+	for i := range args {
+		if err := c.checkRoot(ft.In(i)); err != nil {
+			return err
+		}
+		arg, err := c.resolve(ft.In(i), s)
+		if err != nil {
+			return err
+		}
+		args[i] = arg
+	}
+
+	out := fv.Call(args)
+	if n := len(out); n > 0 && ft.Out(n-1) == errorType && !out[n-1].IsNil() {
+		return out[n-1].Interface().(error)
+	}
+	return nil
+}
+
+// checkRoot 在创建任何实例之前检查依赖，避免循环依赖的构造函数互相等待
+func (c *Container) checkRoot(t reflect.Type) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.providers[t]; !ok {
+		return &MissingError{Type: t}
+	}
+	return c.check(t, nil)
+}
+
+// check 深度优先检查 t 的依赖，调用方需要持有 c.mu 读锁。
+// path 上的类型都已经注册过，依赖链上有单例时不能再出现 Scoped 类型
+func (c *Container) check(t reflect.Type, path []reflect.Type) error {
+	for i, p := range path {
+		if p == t {
+			cycle := append(append([]reflect.Type{}, path[i:]...), t)
+			return &CycleError{Path: cycle}
+		}
+	}
+	p, ok := c.providers[t]
+	if !ok {
+		var required reflect.Type
+		if len(path) > 0 {
+			required = path[len(path)-1]
+		}
+		return &MissingError{Type: t, Required: required}
+	}
+	if p.lifetime == Scoped {
+		for i := len(path) - 1; i >= 0; i-- {
+			if c.providers[path[i]].lifetime == Singleton {
+				return fmt.Errorf("%w: %s -> %s", ErrCaptiveDependency, path[i], t)
+			}
+		}
+	}
+	path = append(path, t)
+	for _, dep := range p.deps {
+		if err := c.check(dep, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve 按生命周期取出或创建实例，依赖已经由 check 检查过
+func (c *Container) resolve(t reflect.Type, s *Scope) (reflect.Value, error) {
+	c.mu.RLock()
+	p := c.providers[t]
+	c.mu.RUnlock()
+
+	switch p.lifetime {
+	case Transient:
+		return c.construct(p, s)
+	case Scoped:
+		if s == nil {
+			return reflect.Value{}, fmt.Errorf("%w: %s", ErrScopeRequired, t)
+		}
+		return c.cached(s.cell(t), p, s)
+	default:
+		// 单例的依赖不能用调用方的作用域，否则会把某次请求的实例永久留在单例里
+		return c.cached(&p.cell, p, nil)
+	}
+}
+
+func (c *Container) cached(cell *instanceCell, p *provider, s *Scope) (reflect.Value, error) {
+	cell.mu.Lock()
+	defer cell.mu.Unlock()
+	if cell.done {
+		return cell.val, nil
+	}
+	val, err := c.construct(p, s)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	cell.val = val
+	cell.done = true
+	return val, nil
+}
+
+func (c *Container) construct(p *provider, s *Scope) (reflect.Value, error) {
+	args := make([]reflect.Value, len(p.deps))
+	for i, dep := range p.deps {
+		arg, err := c.resolve(dep, s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		args[i] = arg
+	}
+	out := p.ctor.Call(args)
+	if p.hasErr && !out[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("container: construct %s: %w", p.out, out[1].Interface().(error))
+	}
+	return out[0], nil
+}
+
+// WriteDOT 以 Graphviz DOT 格式输出依赖图，边从使用方指向依赖
+func (c *Container) WriteDOT(w io.Writer) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var b strings.Builder
+	b.WriteString("digraph container {\n")
+	for _, t := range c.order {
+		fmt.Fprintf(&b, "\t%q [label=%q];\n", t.String(), t.String()+"\n"+c.providers[t].lifetime.String())
+	}
+	for _, t := range c.order {
+		for _, dep := range c.providers[t].deps {
+			fmt.Fprintf(&b, "\t%q -> %q;\n", t.String(), dep.String())
+		}
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// DOT 依赖图的 DOT 文本
+func (c *Container) DOT() string {
+	var b strings.Builder
+	_ = c.WriteDOT(&b)
+	return b.String()
+}
+
+// Scope 作用域，比如一次 HTTP 请求
+type Scope struct {
+	c         *Container
+	mu        sync.Mutex
+	instances map[reflect.Type]*instanceCell
+}
+
+func (s *Scope) cell(t reflect.Type) *instanceCell {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cell, ok := s.instances[t]
+	if !ok {
+		cell = &instanceCell{}
+		s.instances[t] = cell
+	}
+	return cell
+}
+
+// Invoke 在作用域内解析 fn 的参数并调用 fn
+func (s *Scope) Invoke(fn any) error {
+	return s.c.invoke(s, fn)
+}
+
+// ResolveScoped 在作用域内按类型取出实例
+func ResolveScoped[T any](s *Scope) (T, error) {
+	return resolveAs[T](s.c, s)
+}
@@ -0,0 +1,199 @@
+package container
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type (
+	config  struct{ dsn string }
+	db      struct{ cfg *config }
+	request struct{ id int }
+	repo    struct {
+		db  *db
+		req *request
+	}
+	cacheA struct{}
+	cacheB struct{}
+	cacheC struct{}
+)
+
+func TestResolveLifetimes(t *testing.T) {
+	c := New()
+	configs := 0
+	c.MustProvide(func() *config { configs++; return &config{dsn: "mem"} })
+	c.MustProvide(func(cfg *config) *db { return &db{cfg: cfg} }, AsTransient())
+	requests := 0
+	c.MustProvide(func() *request { requests++; return &request{id: requests} }, AsScoped())
+	c.MustProvide(func(db *db, req *request) *repo { return &repo{db: db, req: req} }, AsTransient())
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	db1, _ := Resolve[*db](c)
+	db2, _ := Resolve[*db](c)
+	if db1 == db2 || db1.cfg != db2.cfg || configs != 1 {
+		t.Fatal("transient should be new each time, singleton shared")
+	}
+
+	s1, s2 := c.Scope(), c.Scope()
+	r1, err := ResolveScoped[*repo](s1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1b, _ := ResolveScoped[*repo](s1)
+	r2, _ := ResolveScoped[*repo](s2)
+	if r1 == r1b || r1.req != r1b.req {
+		t.Fatal("scoped instance not shared within a scope")
+	}
+	if r1.req == r2.req || requests != 2 {
+		t.Fatal("scoped instance shared across scopes")
+	}
+}
+
+func TestScopeRequired(t *testing.T) {
+	c := New()
+	c.MustProvide(func() *request { return &request{} }, AsScoped())
+	if _, err := Resolve[*request](c); !errors.Is(err, ErrScopeRequired) {
+		t.Fatalf("err = %v, want ErrScopeRequired", err)
+	}
+	if err := c.Invoke(func(*request) {}); !errors.Is(err, ErrScopeRequired) {
+		t.Fatalf("Invoke err = %v, want ErrScopeRequired", err)
+	}
+	if err := c.Scope().Invoke(func(*request) {}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCaptiveDependency(t *testing.T) {
+	tests := []struct {
+		name    string
+		provide func(c *Container)
+	}{
+		{"direct", func(c *Container) {
+			c.MustProvide(func(req *request) *db { return &db{} })
+		}},
+		{"through transient", func(c *Container) {
+			c.MustProvide(func(req *request) *config { return &config{} }, AsTransient())
+			c.MustProvide(func(cfg *config) *db { return &db{} })
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New()
+			c.MustProvide(func() *request { return &request{} }, AsScoped())
+			tt.provide(c)
+			if err := c.Validate(); !errors.Is(err, ErrCaptiveDependency) {
+				t.Fatalf("Validate err = %v, want ErrCaptiveDependency", err)
+			}
+			// 在作用域内解析也不行，否则第一个作用域的实例会被单例永久持有
+			if _, err := ResolveScoped[*db](c.Scope()); !errors.Is(err, ErrCaptiveDependency) {
+				t.Fatalf("ResolveScoped err = %v, want ErrCaptiveDependency", err)
+			}
+		})
+	}
+
+	// Scoped 依赖单例没有问题
+	c := New()
+	c.MustProvide(func() *config { return &config{} })
+	c.MustProvide(func(cfg *config) *request { return &request{} }, AsScoped())
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCycle(t *testing.T) {
+	c := New()
+	c.MustProvide(func(*cacheB) *cacheA { return &cacheA{} })
+	c.MustProvide(func(*cacheC) *cacheB { return &cacheB{} })
+	c.MustProvide(func(*cacheA) *cacheC { return &cacheC{} })
+
+	var cycle *CycleError
+	if err := c.Validate(); !errors.As(err, &cycle) {
+		t.Fatalf("Validate err = %v, want *CycleError", err)
+	}
+	want := []reflect.Type{
+		reflect.TypeOf(&cacheA{}), reflect.TypeOf(&cacheB{}), reflect.TypeOf(&cacheC{}), reflect.TypeOf(&cacheA{}),
+	}
+	if !reflect.DeepEqual(cycle.Path, want) {
+		t.Fatalf("cycle = %v, want %v", cycle.Path, want)
+	}
+	// 解析时在调用构造函数之前发现环，不会死锁
+	if _, err := Resolve[*cacheB](c); !errors.As(err, &cycle) {
+		t.Fatalf("Resolve err = %v, want *CycleError", err)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	c := New()
+	c.MustProvide(func(*config) *db { return &db{} })
+	var missing *MissingError
+	if err := c.Validate(); !errors.As(err, &missing) || missing.Type != reflect.TypeOf(&config{}) || missing.Required != reflect.TypeOf(&db{}) {
+		t.Fatalf("Validate err = %v, want *MissingError for *config", err)
+	}
+	if _, err := Resolve[*request](c); !errors.As(err, &missing) || missing.Required != nil {
+		t.Fatalf("Resolve err = %v, want *MissingError", err)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	c := New()
+	c.MustProvide(func() *config { return &config{dsn: "mem"} })
+
+	var got *config
+	if err := c.Invoke(func(cfg *config) { got = cfg }); err != nil || got == nil || got.dsn != "mem" {
+		t.Fatalf("Invoke = %v, got %+v", err, got)
+	}
+
+	errRun := errors.New("run failed")
+	if err := c.Invoke(func(*config) error { return errRun }); !errors.Is(err, errRun) {
+		t.Fatalf("Invoke err = %v, want %v", err, errRun)
+	}
+	if err := c.Invoke(func(*config) (int, error) { return 1, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Invoke(42); !errors.Is(err, ErrNotFunc) {
+		t.Fatalf("Invoke(42) err = %v, want ErrNotFunc", err)
+	}
+}
+
+func TestProvide(t *testing.T) {
+	c := New()
+	if err := c.Provide("not a func"); !errors.Is(err, ErrNotFunc) {
+		t.Fatalf("err = %v, want ErrNotFunc", err)
+	}
+	for _, ctor := range []any{
+		func() {},
+		func() error { return nil },
+		func() (*config, int) { return nil, 0 },
+	} {
+		if err := c.Provide(ctor); err == nil {
+			t.Errorf("Provide(%T) accepted", ctor)
+		}
+	}
+	c.MustProvide(func() *config { return &config{} })
+	if err := c.Provide(func() (*config, error) { return nil, nil }); err == nil {
+		t.Fatal("duplicate Provide accepted")
+	}
+}
+
+// TestConstructErrorNotCached 构造失败不缓存，下次解析重新构造
+func TestConstructErrorNotCached(t *testing.T) {
+	c := New()
+	errDown := errors.New("db down")
+	calls := 0
+	c.MustProvide(func() (*db, error) {
+		calls++
+		if calls == 1 {
+			return nil, errDown
+		}
+		return &db{}, nil
+	})
+	if _, err := Resolve[*db](c); !errors.Is(err, errDown) {
+		t.Fatalf("err = %v, want %v", err, errDown)
+	}
+	if _, err := Resolve[*db](c); err != nil || calls != 2 {
+		t.Fatalf("second Resolve = %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	config_mode "design-pattern-go/book-learn/p1-singleton-pattern/config-mode"
+	"design-pattern-go/book-learn/p1-singleton-pattern/container"
+	single_pattern "design-pattern-go/mk-learn/mk-01-single-pattern"
+)
+
+// Request 每次请求一个
+type Request struct {
+	ID int
+}
+
+// UserRepo 依赖连接池，每次请求一个
+type UserRepo struct {
+	Pool    *single_pattern.DbPool
+	Request *Request
+}
+
+func main() {
+	c := container.New()
+
+	requestID := 0
+	c.MustProvide(func() *config_mode.DbConfig {
+		return &config_mode.DbConfig{Host: "127.0.0.1", Port: 3306, User: "root", Pwd: "root"}
+	})
+	c.MustProvide(func(cfg *config_mode.DbConfig) (*single_pattern.DbPool, error) {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+		return single_pattern.NewDbPool(net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)))
+	})
+	c.MustProvide(func() *Request {
+		requestID++
+		return &Request{ID: requestID}
+	}, container.AsScoped())
+	c.MustProvide(func(pool *single_pattern.DbPool, req *Request) *UserRepo {
+		return &UserRepo{Pool: pool, Request: req}
+	}, container.AsTransient())
+
+	if err := c.Validate(); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for i := 0; i < 2; i++ {
+		scope := c.Scope()
+		err := scope.Invoke(func(repo1, repo2 *UserRepo) {
+			fmt.Printf("请求 %d: 连接池 %s, 同一请求共享 Request: %v\n",
+				repo1.Request.ID, repo1.Pool.Addr(), repo1.Request == repo2.Request)
+		})
+		if err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	fmt.Print(c.DOT())
+}
@@ -1,5 +1,11 @@
 package hungry_mode
 
+import (
+	"context"
+
+	"design-pattern-go/book-learn/p1-singleton-pattern/singleton"
+)
+
 /**
 单例模式-饿汉模式
 */
@@ -12,13 +18,47 @@ type databaseConn struct {
 	//变量
 }
 
-var dbConn *databaseConn
+func newDatabaseConn() (*databaseConn, error) {
+	conn := &databaseConn{}
+	if err := singleton.RegisterCloser(closerName, conn.Close); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Close 释放连接，由 singleton.Shutdown 调用
+func (conn *databaseConn) Close(ctx context.Context) error {
+	return nil
+}
+
+// closerName 注册到生命周期管理的名字
+const closerName = "hungry_mode.databaseConn"
+
+var dbConnStats = singleton.Track("hungry_mode.Db")
+
+var dbConn = singleton.NewHolder(singleton.TrackInit(dbConnStats, newDatabaseConn)).OnClose(func(conn *databaseConn) error {
+	singleton.UnregisterCloser(closerName)
+	return conn.Close(context.Background())
+})
 
 func init() {
-	dbConn = &databaseConn{}
+	// 饿汉模式在包初始化时就完成构造，失败直接 panic
+	dbConn.MustGet()
 }
 
-// GetInstance 获取实例
+// Db 获取实例
 func Db() *databaseConn {
-	return dbConn
+	dbConnStats.Access()
+	return dbConn.MustGet()
+}
+
+// Override 测试中替换实例，测试结束自动恢复
+func Override(t singleton.TB, fake *databaseConn) {
+	t.Helper()
+	dbConn.Override(t, fake)
+}
+
+// Reset 关闭并丢弃已创建的实例，下次 Db 时重新创建
+func Reset() error {
+	return dbConn.Reset()
 }
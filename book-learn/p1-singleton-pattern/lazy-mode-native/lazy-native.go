@@ -1,7 +1,12 @@
 package lazy_mode_native
 
 import (
-	"sync"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"design-pattern-go/book-learn/p1-singleton-pattern/singleton"
 )
 
 type Singleton struct {
@@ -9,15 +14,70 @@ type Singleton struct {
 	Port int
 }
 
-var instance *Singleton
-var once sync.Once
-
-func GetInstance() *Singleton {
-	once.Do(func() {
-		instance = &Singleton{
-			Name: "192.168.0.130",
-			Port: 3306,
-		}
-	})
-	return instance
+// Close 释放资源，由 singleton.Shutdown 调用
+func (s *Singleton) Close(ctx context.Context) error {
+	return nil
+}
+
+const (
+	defaultName = "192.168.0.130"
+	defaultPort = 3306
+)
+
+// NewSingleton 校验参数并创建实例
+func NewSingleton(name string, port int) (*Singleton, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("invalid Name is %s", name)
+	}
+	if port <= 0 || port > 65535 {
+		return nil, fmt.Errorf("invalid Port is %d", port)
+	}
+	return &Singleton{
+		Name: name,
+		Port: port,
+	}, nil
+}
+
+// closerName 注册到生命周期管理的名字
+const closerName = "lazy_mode_native.Singleton"
+
+var instanceStats = singleton.Track("lazy_mode_native.GetInstance")
+
+// initTimeout 初始化的总时限，GetInstanceContext 的调用方可以更早放弃等待
+const initTimeout = 10 * time.Second
+
+var instance = singleton.NewHolderCtx(singleton.TrackInitCtx(instanceStats, func(ctx context.Context) (*Singleton, error) {
+	s, err := NewSingleton(defaultName, defaultPort)
+	if err != nil {
+		return nil, err
+	}
+	if err := singleton.RegisterCloser(closerName, s.Close); err != nil {
+		return nil, err
+	}
+	return s, nil
+}), initTimeout).OnClose(func(s *Singleton) error {
+	singleton.UnregisterCloser(closerName)
+	return s.Close(context.Background())
+})
+
+func GetInstance() (*Singleton, error) {
+	instanceStats.Access()
+	return instance.Get()
+}
+
+// GetInstanceContext 同 GetInstance，ctx 取消时不再等待初始化，初始化本身继续进行
+func GetInstanceContext(ctx context.Context) (*Singleton, error) {
+	instanceStats.Access()
+	return instance.GetContext(ctx)
+}
+
+// Override 测试中替换实例，测试结束自动恢复
+func Override(t singleton.TB, fake *Singleton) {
+	t.Helper()
+	instance.Override(t, fake)
+}
+
+// Reset 关闭并丢弃已创建的实例，下次 GetInstance 时重新创建
+func Reset() error {
+	return instance.Reset()
 }
@@ -1,8 +1,10 @@
 package lazy_mode
 
 import (
-	"sync"
-	"sync/atomic"
+	"context"
+	"time"
+
+	"design-pattern-go/book-learn/p1-singleton-pattern/singleton"
 )
 
 type Singleton struct {
@@ -10,23 +12,49 @@ type Singleton struct {
 	Port int
 }
 
-var initialized uint32
-var instance *Singleton
-var mu sync.Locker
+// Close 释放资源，由 singleton.Shutdown 调用
+func (s *Singleton) Close(ctx context.Context) error {
+	return nil
+}
 
-func GetInstance() *Singleton {
+// closerName 注册到生命周期管理的名字
+const closerName = "lazy_mode.Singleton"
 
-	if atomic.LoadUint32(&initialized) == 1 { // 原子操作
-		return instance
-	}
+// 原子操作 + 双重检查的实现见 singleton.LazyCtx，Holder 在此基础上支持测试替换
+var instanceStats = singleton.Track("lazy_mode.GetInstance")
 
-	mu.Lock()
-	defer mu.Unlock()
+// initTimeout 初始化的总时限，GetInstanceContext 的调用方可以更早放弃等待
+const initTimeout = 10 * time.Second
 
-	if initialized == 0 {
-		instance = &Singleton{}
-		atomic.StoreUint32(&initialized, 1)
+var instance = singleton.NewHolderCtx(singleton.TrackInitCtx(instanceStats, func(ctx context.Context) (*Singleton, error) {
+	s := &Singleton{}
+	if err := singleton.RegisterCloser(closerName, s.Close); err != nil {
+		return nil, err
 	}
+	return s, nil
+}), initTimeout).OnClose(func(s *Singleton) error {
+	singleton.UnregisterCloser(closerName)
+	return s.Close(context.Background())
+})
+
+func GetInstance() (*Singleton, error) {
+	instanceStats.Access()
+	return instance.Get()
+}
+
+// GetInstanceContext 同 GetInstance，ctx 取消时不再等待初始化，初始化本身继续进行
+func GetInstanceContext(ctx context.Context) (*Singleton, error) {
+	instanceStats.Access()
+	return instance.GetContext(ctx)
+}
+
+// Override 测试中替换实例，测试结束自动恢复
+func Override(t singleton.TB, fake *Singleton) {
+	t.Helper()
+	instance.Override(t, fake)
+}
 
-	return instance
+// Reset 关闭并丢弃已创建的实例，下次 GetInstance 时重新创建
+func Reset() error {
+	return instance.Reset()
 }
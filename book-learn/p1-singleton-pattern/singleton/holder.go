@@ -0,0 +1,167 @@
+package singleton
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/**
+单例模式-可替换的单例
+包级别的单例在测试里没法换成假实现，也没法在用例之间重置，
+Holder 在 Lazy / LazyCtx 的基础上增加了 Override 和 Reset
+*/
+
+// TB testing.TB 的子集，避免非测试代码引入 testing 包
+type TB interface {
+	Helper()
+	Name() string
+	Cleanup(func())
+	Fatalf(format string, args ...any)
+}
+
+// initializer Lazy 和 LazyCtx 的共同部分
+type initializer[T any] interface {
+	get(ctx context.Context) (T, error)
+	Initialized() bool
+}
+
+func (l *Lazy[T]) get(ctx context.Context) (T, error) {
+	return l.Get()
+}
+
+func (l *LazyCtx[T]) get(ctx context.Context) (T, error) {
+	return l.Get(ctx)
+}
+
+// Holder 可以在测试中替换和重置的单例
+type Holder[T any] struct {
+	newLazy func() initializer[T]
+	closeFn func(T) error
+
+	mu       sync.RWMutex
+	lazy     initializer[T]
+	fake     T
+	owner    string // 当前替换实例的测试名，空表示没有被替换
+	override bool
+}
+
+// NewHolder 创建可替换的单例，fn 为实例的构造函数
+func NewHolder[T any](fn func() (T, error)) *Holder[T] {
+	return newHolder(func() initializer[T] { return NewLazy(fn) })
+}
+
+// NewHolderCtx 创建可替换、支持 context 的单例，初始化方式同 NewLazyCtx
+func NewHolderCtx[T any](fn func(ctx context.Context) (T, error), timeout time.Duration) *Holder[T] {
+	return newHolder(func() initializer[T] { return NewLazyCtx(fn, timeout) })
+}
+
+func newHolder[T any](newLazy func() initializer[T]) *Holder[T] {
+	return &Holder[T]{
+		newLazy: newLazy,
+		lazy:    newLazy(),
+	}
+}
+
+// Get 获取实例，被替换时返回替换的实例
+func (h *Holder[T]) Get() (T, error) {
+	return h.GetContext(context.Background())
+}
+
+// GetContext 获取实例，ctx 取消时放弃等待初始化；
+// NewHolder 创建的单例在当前 goroutine 中初始化，不受 ctx 影响
+func (h *Holder[T]) GetContext(ctx context.Context) (T, error) {
+	h.mu.RLock()
+	if h.override {
+		fake := h.fake
+		h.mu.RUnlock()
+		return fake, nil
+	}
+	lazy := h.lazy
+	h.mu.RUnlock()
+	return lazy.get(ctx)
+}
+
+// MustGet 获取实例，初始化失败时 panic
+func (h *Holder[T]) MustGet() T {
+	val, err := h.Get()
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// Override 在测试 t 中用 fake 替换实例，t 结束时自动恢复。
+// 同一时刻只允许一个测试替换，并行测试重复替换会直接失败
+func (h *Holder[T]) Override(t TB, fake T) {
+	t.Helper()
+
+	h.mu.Lock()
+	if h.override {
+		owner := h.owner
+		h.mu.Unlock()
+		t.Fatalf("singleton: concurrent override, already overridden by %s", owner)
+		return
+	}
+	h.fake = fake
+	h.owner = t.Name()
+	h.override = true
+	h.mu.Unlock()
+
+	t.Cleanup(func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		var zero T
+		h.fake = zero
+		h.owner = ""
+		h.override = false
+	})
+}
+
+// Overridden 当前是否被替换
+func (h *Holder[T]) Overridden() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.override
+}
+
+// Fake 被替换时返回替换的实例和 true，用于让其他获取方式也能感知替换
+func (h *Holder[T]) Fake() (T, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.fake, h.override
+}
+
+// OnClose 设置 Reset 时释放旧实例的函数，一般在其中取消注册的 closer 并关闭实例
+func (h *Holder[T]) OnClose(fn func(T) error) *Holder[T] {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closeFn = fn
+	return h
+}
+
+// Reset 丢弃已经创建的实例，下次 Get 重新调用构造函数；不影响 Override。
+// 旧实例已经初始化成功时交给 OnClose 设置的函数释放，返回释放的错误
+func (h *Holder[T]) Reset() error {
+	h.mu.Lock()
+	old := h.lazy
+	h.lazy = h.newLazy()
+	closeFn := h.closeFn
+	h.mu.Unlock()
+
+	if closeFn == nil || !old.Initialized() {
+		return nil
+	}
+	val, err := old.get(context.Background())
+	if err != nil {
+		return nil
+	}
+	return closeFn(val)
+}
+
+// Initialized 构造函数是否已经执行完成
+func (h *Holder[T]) Initialized() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.lazy.Initialized()
+}
@@ -0,0 +1,209 @@
+package singleton
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeTB 记录 Cleanup 和 Fatalf，用来检查 Override 失败的情况
+type fakeTB struct {
+	name     string
+	cleanups []func()
+	fatal    string
+}
+
+func (t *fakeTB) Helper()           {}
+func (t *fakeTB) Name() string      { return t.name }
+func (t *fakeTB) Cleanup(fn func()) { t.cleanups = append(t.cleanups, fn) }
+func (t *fakeTB) Fatalf(format string, args ...any) {
+	t.fatal = fmt.Sprintf(format, args...)
+}
+
+// finish 按 testing 的顺序执行 Cleanup
+func (t *fakeTB) finish() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+	t.cleanups = nil
+}
+
+func newCountingHolder() (*Holder[*int], *int) {
+	var calls int
+	return NewHolder(func() (*int, error) {
+		calls++
+		v := calls
+		return &v, nil
+	}), &calls
+}
+
+func TestHolderOverrideRestoredOnCleanup(t *testing.T) {
+	h, _ := newCountingHolder()
+	orig := h.MustGet()
+
+	fake := new(int)
+	tb := &fakeTB{name: "TestA"}
+	h.Override(tb, fake)
+	if got := h.MustGet(); got != fake {
+		t.Fatal("Get did not return the fake")
+	}
+	if got, ok := h.Fake(); !ok || got != fake {
+		t.Fatal("Fake did not report the override")
+	}
+
+	tb.finish()
+	if h.Overridden() {
+		t.Fatal("override not restored after cleanup")
+	}
+	if _, ok := h.Fake(); ok {
+		t.Fatal("Fake still reports an override")
+	}
+	if got := h.MustGet(); got != orig {
+		t.Fatal("real instance not restored after cleanup")
+	}
+}
+
+func TestHolderConcurrentOverride(t *testing.T) {
+	h, _ := newCountingHolder()
+	first := &fakeTB{name: "TestA"}
+	h.Override(first, new(int))
+
+	second := &fakeTB{name: "TestB"}
+	h.Override(second, new(int))
+	if second.fatal == "" {
+		t.Fatal("second override was not rejected")
+	}
+	if want := "TestA"; !strings.Contains(second.fatal, want) {
+		t.Fatalf("fatal %q does not name the owner %s", second.fatal, want)
+	}
+	if len(second.cleanups) != 0 {
+		t.Fatal("rejected override registered a cleanup")
+	}
+
+	// 第一个测试结束后可以再次替换
+	first.finish()
+	third := &fakeTB{name: "TestC"}
+	h.Override(third, new(int))
+	if third.fatal != "" {
+		t.Fatalf("override after cleanup failed: %s", third.fatal)
+	}
+	third.finish()
+}
+
+func TestHolderReset(t *testing.T) {
+	h, calls := newCountingHolder()
+	if h.Initialized() {
+		t.Fatal("initialized before Get")
+	}
+	first := h.MustGet()
+	if h.MustGet() != first || *calls != 1 {
+		t.Fatal("constructor called more than once")
+	}
+
+	// Reset 不影响正在进行的替换
+	tb := &fakeTB{name: "TestA"}
+	fake := new(int)
+	h.Override(tb, fake)
+	h.Reset()
+	if h.MustGet() != fake {
+		t.Fatal("Reset dropped the override")
+	}
+	tb.finish()
+
+	if second := h.MustGet(); second == first || *calls != 2 {
+		t.Fatal("Reset did not rebuild the instance")
+	}
+}
+
+// TestHolderGetContext GetInstanceContext 的行为：初始化卡住时调用方取消等待，
+// 初始化本身继续进行，完成后所有获取方式拿到同一个实例
+func TestHolderGetContext(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var attempts int32
+	h := NewHolderCtx(func(ctx context.Context) (*int, error) {
+		atomic.AddInt32(&attempts, 1)
+		close(started)
+		<-release
+		return new(int), nil
+	}, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		_, err := h.GetContext(ctx)
+		errc <- err
+	}()
+	<-started
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	close(release)
+	got, err := h.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := h.GetContext(context.Background()); again != got {
+		t.Fatal("GetContext returned a different instance")
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Fatalf("%d attempts, want 1", n)
+	}
+
+	tb := &fakeTB{name: "TestA"}
+	fake := new(int)
+	h.Override(tb, fake)
+	if v, _ := h.GetContext(context.Background()); v != fake {
+		t.Fatal("GetContext ignored Override")
+	}
+	tb.finish()
+}
+
+// TestHolderResetCloses Reset 关闭旧实例，并把它的 closer 从生命周期管理中移除
+func TestHolderResetCloses(t *testing.T) {
+	lifecycle := NewLifecycle()
+	var created, closed []*int
+	h := NewHolder(func() (*int, error) {
+		v := new(int)
+		created = append(created, v)
+		err := lifecycle.Register("holder", func(ctx context.Context) error {
+			t.Errorf("stale closer of instance %d called", len(created))
+			return nil
+		})
+		return v, err
+	}).OnClose(func(v *int) error {
+		lifecycle.Unregister("holder")
+		closed = append(closed, v)
+		return nil
+	})
+
+	// 还没有创建实例，不需要关闭
+	if err := h.Reset(); err != nil || len(closed) != 0 {
+		t.Fatalf("Reset before Get: %v, closed %d", err, len(closed))
+	}
+
+	first := h.MustGet()
+	if err := h.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if len(closed) != 1 || closed[0] != first {
+		t.Fatalf("closed %v, want the first instance", closed)
+	}
+	if err := lifecycle.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if second := h.MustGet(); second == first {
+		t.Fatal("Reset did not rebuild the instance")
+	}
+	errClose := errors.New("close failed")
+	h.OnClose(func(*int) error { return errClose })
+	if err := h.Reset(); !errors.Is(err, errClose) {
+		t.Fatalf("Reset err = %v, want %v", err, errClose)
+	}
+}
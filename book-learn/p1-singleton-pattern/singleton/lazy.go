@@ -0,0 +1,55 @@
+package singleton
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+/**
+单例模式-泛型懒加载
+*/
+
+// Lazy 泛型懒汉单例，首次 Get 时调用构造函数，结果（包括错误）只计算一次
+type Lazy[T any] struct {
+	newFn func() (T, error)
+
+	done uint32
+	mu   sync.Mutex
+	val  T
+	err  error
+}
+
+// NewLazy 创建一个懒加载单例，fn 为实例的构造函数
+func NewLazy[T any](fn func() (T, error)) *Lazy[T] {
+	return &Lazy[T]{newFn: fn}
+}
+
+// Get 获取实例，构造函数只会被成功执行一次；若构造函数 panic，下次调用会重新执行
+func (l *Lazy[T]) Get() (T, error) {
+	if atomic.LoadUint32(&l.done) == 1 { // 原子操作，保证当前读
+		return l.val, l.err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.done == 0 {
+		l.val, l.err = l.newFn()
+		atomic.StoreUint32(&l.done, 1)
+	}
+	return l.val, l.err
+}
+
+// MustGet 获取实例，初始化失败时 panic，适合饿汉模式在 init 中使用
+func (l *Lazy[T]) MustGet() T {
+	val, err := l.Get()
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// Initialized 构造函数是否已经执行完成（无论成功与否）
+func (l *Lazy[T]) Initialized() bool {
+	return atomic.LoadUint32(&l.done) == 1
+}
@@ -0,0 +1,104 @@
+package singleton
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+单例模式-支持 context 的懒加载
+初始化在单独的 goroutine 中执行，所有调用方共享同一次初始化，每个调用方可以按自己的 ctx 放弃等待，
+放弃等待不会中断其他调用方共享的初始化；初始化超时不会被永久缓存
+*/
+
+// LazyCtx 支持 context 的泛型懒汉单例
+type LazyCtx[T any] struct {
+	newFn   func(ctx context.Context) (T, error)
+	timeout time.Duration
+
+	done uint32
+	mu   sync.Mutex
+	call *lazyCtxCall[T] // 正在进行的初始化
+	val  T
+	err  error
+}
+
+// lazyCtxCall 一次初始化，等待者在 ready 关闭后读取结果
+type lazyCtxCall[T any] struct {
+	ready chan struct{}
+	val   T
+	err   error
+}
+
+// NewLazyCtx 创建支持 context 的懒加载单例。
+// timeout 为每次初始化的总时限，通过 ctx 传给 fn，<=0 表示不限制
+func NewLazyCtx[T any](fn func(ctx context.Context) (T, error), timeout time.Duration) *LazyCtx[T] {
+	return &LazyCtx[T]{newFn: fn, timeout: timeout}
+}
+
+// Get 获取实例，初始化还没完成时等待，ctx 取消时返回 ctx.Err()
+func (l *LazyCtx[T]) Get(ctx context.Context) (T, error) {
+	if atomic.LoadUint32(&l.done) == 1 {
+		return l.val, l.err
+	}
+
+	l.mu.Lock()
+	if l.done == 1 {
+		l.mu.Unlock()
+		return l.val, l.err
+	}
+	if l.call == nil {
+		l.call = &lazyCtxCall[T]{ready: make(chan struct{})}
+		go l.run(l.call)
+	}
+	call := l.call
+	l.mu.Unlock()
+
+	select {
+	case <-call.ready:
+		return call.val, call.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// run 初始化不继承任何调用方的 ctx，只受 timeout 限制。
+// 因为超时或取消失败时不保存结果，下一次 Get 重新初始化；其他结果（包括错误）只计算一次
+func (l *LazyCtx[T]) run(call *lazyCtxCall[T]) {
+	ctx := context.Background()
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			call.err = fmt.Errorf("singleton: init panic: %v", p)
+		}
+		l.mu.Lock()
+		if call.err != nil && (ctx.Err() != nil || isContextErr(call.err)) {
+			l.call = nil
+		} else {
+			l.val, l.err = call.val, call.err
+			atomic.StoreUint32(&l.done, 1)
+		}
+		l.mu.Unlock()
+		close(call.ready)
+	}()
+	call.val, call.err = l.newFn(ctx)
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// Initialized 初始化是否已经完成（无论成功与否）
+func (l *LazyCtx[T]) Initialized() bool {
+	return atomic.LoadUint32(&l.done) == 1
+}
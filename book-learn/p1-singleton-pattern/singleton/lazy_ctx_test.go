@@ -0,0 +1,80 @@
+package singleton
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestLazyCtxRetryAfterTimeout 第一次初始化超时，结果不缓存，之后的调用重新初始化并成功
+func TestLazyCtxRetryAfterTimeout(t *testing.T) {
+	var attempts int32
+	l := NewLazyCtx(func(ctx context.Context) (string, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			<-ctx.Done() // 第一次卡住直到总时限
+			return "", ctx.Err()
+		}
+		return "pool", nil
+	}, 20*time.Millisecond)
+
+	if _, err := l.Get(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("first Get err = %v, want context.DeadlineExceeded", err)
+	}
+	if l.Initialized() {
+		t.Fatal("timed out init was cached")
+	}
+	got, err := l.Get(context.Background())
+	if err != nil || got != "pool" {
+		t.Fatalf("second Get = %q, %v", got, err)
+	}
+	if !l.Initialized() {
+		t.Fatal("successful init not cached")
+	}
+	if _, _ = l.Get(context.Background()); atomic.LoadInt32(&attempts) != 2 {
+		t.Fatalf("%d attempts, want 2", attempts)
+	}
+}
+
+// TestLazyCtxCachesOtherErrors 其他错误只计算一次
+func TestLazyCtxCachesOtherErrors(t *testing.T) {
+	errInit := errors.New("bad config")
+	var attempts int32
+	l := NewLazyCtx(func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&attempts, 1)
+		return 0, errInit
+	}, 0)
+	for i := 0; i < 3; i++ {
+		if _, err := l.Get(context.Background()); !errors.Is(err, errInit) {
+			t.Fatalf("err = %v, want %v", err, errInit)
+		}
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Fatalf("%d attempts, want 1", n)
+	}
+}
+
+// TestLazyCtxCallerCancel 调用方放弃等待不影响正在进行的初始化
+func TestLazyCtxCallerCancel(t *testing.T) {
+	release := make(chan struct{})
+	var attempts int32
+	l := NewLazyCtx(func(ctx context.Context) (int, error) {
+		atomic.AddInt32(&attempts, 1)
+		<-release
+		return 42, nil
+	}, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.Get(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	close(release)
+	if v, err := l.Get(context.Background()); err != nil || v != 42 {
+		t.Fatalf("Get = %d, %v", v, err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Fatalf("%d attempts, want 1", n)
+	}
+}
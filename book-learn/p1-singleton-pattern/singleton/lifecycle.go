@@ -0,0 +1,223 @@
+package singleton
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+/**
+单例生命周期
+每个单例注册自己的关闭函数和依赖，Shutdown 时按依赖的逆序关闭：
+先关闭依赖方，再关闭被依赖方
+*/
+
+// CloseFunc 关闭单例，需要在 ctx 取消时尽快返回
+type CloseFunc func(ctx context.Context) error
+
+// CycleError 注册时发现循环依赖
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return "singleton: dependency cycle: " + strings.Join(e.Path, " -> ")
+}
+
+// ShutdownError Shutdown 过程中所有关闭失败的错误
+type ShutdownError struct {
+	Errs []error
+}
+
+func (e *ShutdownError) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("singleton: shutdown failed (%d errors): %s", len(e.Errs), strings.Join(msgs, "; "))
+}
+
+func (e *ShutdownError) Unwrap() []error {
+	return e.Errs
+}
+
+type lifecycleNode struct {
+	name    string
+	closeFn CloseFunc
+	deps    []string
+}
+
+// Lifecycle 单例关闭注册表
+type Lifecycle struct {
+	mu    sync.Mutex
+	nodes map[string]*lifecycleNode
+	order []string // 注册顺序，保证关闭顺序稳定
+}
+
+// NewLifecycle 创建关闭注册表
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{
+		nodes: make(map[string]*lifecycleNode),
+	}
+}
+
+// Register 注册名为 name 的单例的关闭函数，deps 为它依赖的单例名。
+// 依赖可以晚于自己注册；同名重复注册会替换之前的注册；形成循环依赖时返回 *CycleError
+func (l *Lifecycle) Register(name string, closeFn CloseFunc, deps ...string) error {
+	if closeFn == nil {
+		return fmt.Errorf("singleton: nil close func for %s", name)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	old, exists := l.nodes[name]
+	l.nodes[name] = &lifecycleNode{name: name, closeFn: closeFn, deps: deps}
+	if path := l.findCycle(name); path != nil {
+		if exists {
+			l.nodes[name] = old
+		} else {
+			delete(l.nodes, name)
+		}
+		return &CycleError{Path: path}
+	}
+	if !exists {
+		l.order = append(l.order, name)
+	}
+	return nil
+}
+
+// Unregister 取消注册，单例提前关闭时使用
+func (l *Lifecycle) Unregister(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.nodes[name]; !ok {
+		return
+	}
+	delete(l.nodes, name)
+	for i, n := range l.order {
+		if n == name {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// findCycle 从 start 出发查找回到 start 的路径，调用方需要持有 l.mu
+func (l *Lifecycle) findCycle(start string) []string {
+	visited := make(map[string]bool)
+	var path []string
+	var dfs func(name string) bool
+	dfs = func(name string) bool {
+		path = append(path, name)
+		if node, ok := l.nodes[name]; ok {
+			for _, dep := range node.deps {
+				if dep == start {
+					path = append(path, dep)
+					return true
+				}
+				if visited[dep] {
+					continue
+				}
+				visited[dep] = true
+				if dfs(dep) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if dfs(start) {
+		return path
+	}
+	return nil
+}
+
+// shutdownOrder 依赖方在前、被依赖方在后的关闭顺序，调用方需要持有 l.mu
+func (l *Lifecycle) shutdownOrder() []*lifecycleNode {
+	visited := make(map[string]bool)
+	initOrder := make([]*lifecycleNode, 0, len(l.nodes))
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		node, ok := l.nodes[name]
+		if !ok {
+			return
+		}
+		for _, dep := range node.deps {
+			visit(dep)
+		}
+		initOrder = append(initOrder, node)
+	}
+	for _, name := range l.order {
+		visit(name)
+	}
+
+	order := make([]*lifecycleNode, len(initOrder))
+	for i, node := range initOrder {
+		order[len(initOrder)-1-i] = node
+	}
+	return order
+}
+
+// Shutdown 按依赖的逆序关闭所有单例，关闭失败不影响后续单例，错误汇总后返回。
+// ctx 到期后未关闭的单例不再等待，记为超时错误。Shutdown 之后注册表被清空
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	order := l.shutdownOrder()
+	l.nodes = make(map[string]*lifecycleNode)
+	l.order = nil
+	l.mu.Unlock()
+
+	var errs []error
+	for _, node := range order {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: not closed: %w", node.name, err))
+			continue
+		}
+		if err := closeWithContext(ctx, node.closeFn); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", node.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return &ShutdownError{Errs: errs}
+	}
+	return nil
+}
+
+// closeWithContext 关闭函数不理会 ctx 时也能按时返回
+func closeWithContext(ctx context.Context, closeFn CloseFunc) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- closeFn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var defaultLifecycle = NewLifecycle()
+
+// RegisterCloser 在默认注册表中注册单例的关闭函数
+func RegisterCloser(name string, closeFn CloseFunc, deps ...string) error {
+	return defaultLifecycle.Register(name, closeFn, deps...)
+}
+
+// UnregisterCloser 从默认注册表中取消注册
+func UnregisterCloser(name string) {
+	defaultLifecycle.Unregister(name)
+}
+
+// Shutdown 关闭默认注册表中的所有单例，一般在进程退出前调用
+func Shutdown(ctx context.Context) error {
+	return defaultLifecycle.Shutdown(ctx)
+}
@@ -0,0 +1,117 @@
+package singleton
+
+import (
+	"sync"
+)
+
+/**
+多例模式
+每个 key 对应一个单例，不同 key 的初始化互不阻塞
+*/
+
+type multitonEntry[T any] struct {
+	lazy *Lazy[T]
+}
+
+// Multiton 按 key 懒加载的多例注册表
+type Multiton[K comparable, T any] struct {
+	newFn   func(K) (T, error)
+	closeFn func(K, T) error
+
+	mu      sync.Mutex
+	entries map[K]*multitonEntry[T]
+	order   []K // key 的创建顺序，Keys 按这个顺序返回
+}
+
+// NewMultiton 创建多例注册表，fn 为每个 key 的构造函数
+func NewMultiton[K comparable, T any](fn func(K) (T, error)) *Multiton[K, T] {
+	return &Multiton[K, T]{
+		newFn:   fn,
+		entries: make(map[K]*multitonEntry[T]),
+	}
+}
+
+// OnClose 设置 Evict 时释放实例的函数
+func (m *Multiton[K, T]) OnClose(fn func(K, T) error) *Multiton[K, T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closeFn = fn
+	return m
+}
+
+// Get 获取 key 对应的实例，同一个 key 并发调用只会初始化一次。
+// 初始化失败的 key 会被移除，下次 Get 重新初始化
+func (m *Multiton[K, T]) Get(key K) (T, error) {
+	m.mu.Lock()
+	e, ok := m.entries[key]
+	if !ok {
+		e = &multitonEntry[T]{
+			lazy: NewLazy(func() (T, error) {
+				return m.newFn(key)
+			}),
+		}
+		m.entries[key] = e
+		m.order = append(m.order, key)
+	}
+	m.mu.Unlock()
+
+	// 在注册表锁之外初始化，慢的 key 不会阻塞其他 key
+	val, err := e.lazy.Get()
+	if err != nil {
+		m.mu.Lock()
+		if m.entries[key] == e {
+			m.remove(key)
+		}
+		m.mu.Unlock()
+	}
+	return val, err
+}
+
+// Evict 移除 key 并释放对应的实例；key 正在初始化时会等待初始化结束
+func (m *Multiton[K, T]) Evict(key K) error {
+	m.mu.Lock()
+	e, ok := m.entries[key]
+	if ok {
+		m.remove(key)
+	}
+	closeFn := m.closeFn
+	m.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	val, err := e.lazy.Get()
+	if err != nil || closeFn == nil {
+		return nil
+	}
+	return closeFn(key, val)
+}
+
+// Keys 已经初始化成功的 key，按创建顺序返回
+func (m *Multiton[K, T]) Keys() []K {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]K, 0, len(m.order))
+	for _, key := range m.order {
+		e := m.entries[key]
+		if !e.lazy.Initialized() {
+			continue
+		}
+		if _, err := e.lazy.Get(); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// remove 调用方需要持有 m.mu
+func (m *Multiton[K, T]) remove(key K) {
+	delete(m.entries, key)
+	for i, k := range m.order {
+		if k == key {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package singleton
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type conn struct {
+	addr   string
+	closed bool
+}
+
+func TestMultitonKeyIsolation(t *testing.T) {
+	errDown := errors.New("down")
+	m := NewMultiton(func(addr string) (*conn, error) {
+		if addr == "bad" {
+			return nil, errDown
+		}
+		return &conn{addr: addr}, nil
+	})
+
+	a, err := m.Get("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := m.Get("b")
+	a2, _ := m.Get("a")
+	if a != a2 || a == b || a.addr != "a" || b.addr != "b" {
+		t.Fatalf("a=%+v a2=%+v b=%+v", a, a2, b)
+	}
+	// 失败的 key 不影响其他 key，也不出现在 Keys 里
+	if _, err := m.Get("bad"); !errors.Is(err, errDown) {
+		t.Fatalf("err = %v, want %v", err, errDown)
+	}
+	if got := m.Keys(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("Keys = %v", got)
+	}
+}
+
+func TestMultitonConcurrentFirstAccess(t *testing.T) {
+	var calls sync.Map
+	release := make(chan struct{})
+	m := NewMultiton(func(key int) (*int64, error) {
+		n, _ := calls.LoadOrStore(key, new(int64))
+		atomic.AddInt64(n.(*int64), 1)
+		<-release
+		v := int64(key)
+		return &v, nil
+	})
+
+	const keys, callers = 4, 16
+	got := make([][]*int64, keys)
+	var wg sync.WaitGroup
+	for k := 0; k < keys; k++ {
+		got[k] = make([]*int64, callers)
+		for i := 0; i < callers; i++ {
+			k, i := k, i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, err := m.Get(k)
+				if err != nil {
+					t.Error(err)
+				}
+				got[k][i] = v
+			}()
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	for k := 0; k < keys; k++ {
+		n, _ := calls.Load(k)
+		if c := atomic.LoadInt64(n.(*int64)); c != 1 {
+			t.Errorf("key %d initialized %d times", k, c)
+		}
+		for _, v := range got[k] {
+			if v != got[k][0] || *v != int64(k) {
+				t.Fatalf("key %d got different instances", k)
+			}
+		}
+	}
+}
+
+func TestMultitonEvict(t *testing.T) {
+	var closed []string
+	m := NewMultiton(func(addr string) (*conn, error) {
+		return &conn{addr: addr}, nil
+	}).OnClose(func(addr string, c *conn) error {
+		c.closed = true
+		closed = append(closed, addr)
+		return nil
+	})
+
+	old, _ := m.Get("a")
+	if err := m.Evict("a"); err != nil {
+		t.Fatal(err)
+	}
+	if !old.closed || !reflect.DeepEqual(closed, []string{"a"}) {
+		t.Fatalf("closed = %v", closed)
+	}
+	if err := m.Evict("missing"); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Keys()) != 0 {
+		t.Fatalf("Keys = %v after Evict", m.Keys())
+	}
+	if c, _ := m.Get("a"); c == old || c.closed {
+		t.Fatal("Get after Evict returned the closed instance")
+	}
+}
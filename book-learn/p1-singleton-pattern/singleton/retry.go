@@ -0,0 +1,224 @@
+package singleton
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+单例模式-失败重试
+sync.Once 会把第一次失败永久缓存下来，Retry 只缓存成功的结果，
+失败后按指数退避 + 抖动等待，下一次调用再重新初始化
+*/
+
+// Backoff 指数退避参数
+type Backoff struct {
+	Initial    time.Duration // 第一次失败后的等待时间
+	Max        time.Duration // 等待时间上限
+	Multiplier float64       // 每次失败等待时间的倍数
+	Jitter     float64       // 抖动比例，取值 [0,1]，0 表示不抖动
+}
+
+// DefaultBackoff 默认退避参数
+var DefaultBackoff = Backoff{
+	Initial:    100 * time.Millisecond,
+	Max:        30 * time.Second,
+	Multiplier: 2,
+	Jitter:     0.2,
+}
+
+// ErrInvalidBackoff 退避参数不合法
+var ErrInvalidBackoff = errors.New("singleton: invalid backoff")
+
+// Validate 检查退避参数：时间不能为负，Multiplier 不小于 1，Jitter 在 [0,1] 内
+func (b Backoff) Validate() error {
+	switch {
+	case b.Initial < 0:
+		return fmt.Errorf("%w: initial %s", ErrInvalidBackoff, b.Initial)
+	case b.Max < 0:
+		return fmt.Errorf("%w: max %s", ErrInvalidBackoff, b.Max)
+	case !(b.Multiplier >= 1): // 同时排除 NaN
+		return fmt.Errorf("%w: multiplier %v", ErrInvalidBackoff, b.Multiplier)
+	case !(b.Jitter >= 0 && b.Jitter <= 1):
+		return fmt.Errorf("%w: jitter %v", ErrInvalidBackoff, b.Jitter)
+	}
+	return nil
+}
+
+// Delay 第 attempt 次失败（从 1 开始）之后需要等待的时间；
+// Max 为 0 时不超过 time.Duration 能表示的最大值
+func (b Backoff) Delay(attempt int) time.Duration {
+	if attempt <= 0 || b.Initial <= 0 {
+		return 0
+	}
+	limit := float64(math.MaxInt64)
+	if b.Max > 0 {
+		limit = float64(b.Max)
+	}
+	d := float64(b.Initial)
+	if b.Multiplier > 1 {
+		d *= math.Pow(b.Multiplier, float64(attempt-1)) // 溢出时为 +Inf，下面截断
+	}
+	if d > limit {
+		d = limit
+	}
+	if b.Jitter > 0 {
+		// 在 [d*(1-jitter), d*(1+jitter)] 范围内随机
+		d += d * b.Jitter * (2*rand.Float64() - 1)
+	}
+	// float64(math.MaxInt64) 实际是 2^63，直接转换会溢出成负数
+	if d >= float64(math.MaxInt64) {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// RetryOption 设置 Retry 的参数
+type RetryOption func(b *Backoff)
+
+func WithInitialBackoff(d time.Duration) RetryOption {
+	return func(b *Backoff) {
+		b.Initial = d
+	}
+}
+
+func WithMaxBackoff(d time.Duration) RetryOption {
+	return func(b *Backoff) {
+		b.Max = d
+	}
+}
+
+func WithMultiplier(m float64) RetryOption {
+	return func(b *Backoff) {
+		b.Multiplier = m
+	}
+}
+
+func WithJitter(j float64) RetryOption {
+	return func(b *Backoff) {
+		b.Jitter = j
+	}
+}
+
+// RetryError 初始化失败且仍处于退避期时返回的错误
+type RetryError struct {
+	Attempts int       // 已经失败的次数
+	Next     time.Time // 下一次允许重试的时间
+	Err      error     // 最近一次失败的错误
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("singleton: init failed %d times, next retry at %s: %v",
+		e.Attempts, e.Next.Format(time.RFC3339Nano), e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// retryCall 一次正在进行的初始化，并发调用方共享同一次结果
+type retryCall[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// Retry 失败可重试的单例
+type Retry[T any] struct {
+	newFn   func() (T, error)
+	backoff Backoff
+
+	initialized uint32
+	mu          sync.Mutex
+	val         T
+	call        *retryCall[T]
+	attempts    int
+	lastErr     error
+	next        time.Time
+}
+
+// NewRetry 创建失败可重试的单例，退避参数不合法时 panic
+func NewRetry[T any](fn func() (T, error), opts ...RetryOption) *Retry[T] {
+	b := DefaultBackoff
+	for _, opt := range opts {
+		opt(&b)
+	}
+	if err := b.Validate(); err != nil {
+		panic(err)
+	}
+	return &Retry[T]{newFn: fn, backoff: b}
+}
+
+// Get 获取实例。
+// 已经初始化成功直接返回；有初始化正在进行时等待其结果；
+// 仍处于退避期时返回 *RetryError；否则发起一次新的初始化
+func (r *Retry[T]) Get() (T, error) {
+	if atomic.LoadUint32(&r.initialized) == 1 {
+		return r.val, nil
+	}
+
+	r.mu.Lock()
+	if r.initialized == 1 {
+		r.mu.Unlock()
+		return r.val, nil
+	}
+	if c := r.call; c != nil {
+		r.mu.Unlock()
+		<-c.done
+		return c.val, c.err
+	}
+	if r.lastErr != nil && time.Now().Before(r.next) {
+		err := &RetryError{Attempts: r.attempts, Next: r.next, Err: r.lastErr}
+		r.mu.Unlock()
+		var zero T
+		return zero, err
+	}
+	c := &retryCall[T]{done: make(chan struct{})}
+	r.call = c
+	r.mu.Unlock()
+
+	c.val, c.err = r.attempt()
+
+	r.mu.Lock()
+	if c.err == nil {
+		r.val = c.val
+		r.lastErr = nil
+		atomic.StoreUint32(&r.initialized, 1)
+	} else {
+		r.attempts++
+		r.lastErr = c.err
+		r.next = time.Now().Add(r.backoff.Delay(r.attempts))
+	}
+	r.call = nil
+	r.mu.Unlock()
+	close(c.done)
+
+	return c.val, c.err
+}
+
+// attempt 执行一次构造函数，panic 转换成错误，避免等待方永久阻塞
+func (r *Retry[T]) attempt() (val T, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("singleton: init panic: %v", p)
+		}
+	}()
+	return r.newFn()
+}
+
+// Initialized 是否已经初始化成功
+func (r *Retry[T]) Initialized() bool {
+	return atomic.LoadUint32(&r.initialized) == 1
+}
+
+// LastError 最近一次初始化失败的错误，成功后为 nil
+func (r *Retry[T]) LastError() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastErr
+}
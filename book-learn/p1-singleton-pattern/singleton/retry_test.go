@@ -0,0 +1,98 @@
+package singleton
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+		attempt int
+		want    time.Duration
+	}{
+		{"first", Backoff{Initial: time.Second, Multiplier: 2}, 1, time.Second},
+		{"doubles", Backoff{Initial: time.Second, Multiplier: 2}, 4, 8 * time.Second},
+		{"capped by max", Backoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2}, 10, 5 * time.Second},
+		{"no max, no overflow", Backoff{Initial: time.Second, Multiplier: 2}, 2000, math.MaxInt64},
+		{"no max, huge attempt", Backoff{Initial: time.Second, Multiplier: 1.5}, math.MaxInt32, math.MaxInt64},
+		{"multiplier 1", Backoff{Initial: time.Second, Multiplier: 1}, 100, time.Second},
+		{"attempt 0", Backoff{Initial: time.Second, Multiplier: 2}, 0, 0},
+		{"no initial", Backoff{Multiplier: 2}, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.backoff.Delay(tt.attempt); got != tt.want {
+				t.Fatalf("Delay(%d) = %s, want %s", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffDelayJitter(t *testing.T) {
+	b := Backoff{Initial: time.Second, Multiplier: 2, Jitter: 1}
+	for attempt := 1; attempt <= 3000; attempt++ {
+		if d := b.Delay(attempt); d < 0 {
+			t.Fatalf("Delay(%d) = %s, want >= 0", attempt, d)
+		}
+	}
+}
+
+func TestBackoffValidate(t *testing.T) {
+	for _, b := range []Backoff{
+		{Initial: -time.Second, Multiplier: 2},
+		{Max: -time.Second, Multiplier: 2},
+		{Multiplier: 0.5},
+		{Multiplier: math.NaN()},
+		{Multiplier: 2, Jitter: 1.5},
+		{Multiplier: 2, Jitter: -0.1},
+	} {
+		if err := b.Validate(); !errors.Is(err, ErrInvalidBackoff) {
+			t.Errorf("Validate(%+v) = %v, want ErrInvalidBackoff", b, err)
+		}
+	}
+	if err := DefaultBackoff.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewRetryRejectsMultiplier(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrInvalidBackoff) {
+			t.Fatalf("recovered %v, want ErrInvalidBackoff", err)
+		}
+	}()
+	NewRetry(func() (int, error) { return 0, nil }, WithMultiplier(0.5))
+}
+
+func TestRetryAfterBackoff(t *testing.T) {
+	errDown := errors.New("db down")
+	calls := 0
+	r := NewRetry(func() (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errDown
+		}
+		return 42, nil
+	}, WithInitialBackoff(20*time.Millisecond), WithJitter(0))
+
+	if _, err := r.Get(); !errors.Is(err, errDown) {
+		t.Fatalf("first Get err = %v, want %v", err, errDown)
+	}
+	// 退避期内不重新初始化
+	var retryErr *RetryError
+	if _, err := r.Get(); !errors.As(err, &retryErr) || retryErr.Attempts != 1 || !errors.Is(err, errDown) {
+		t.Fatalf("Get during backoff err = %v, want *RetryError", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if v, err := r.Get(); err != nil || v != 42 {
+		t.Fatalf("Get after backoff = %d, %v", v, err)
+	}
+	if calls != 2 || r.LastError() != nil || !r.Initialized() {
+		t.Fatalf("calls %d, last error %v", calls, r.LastError())
+	}
+}
@@ -0,0 +1,167 @@
+package singleton
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+单例统计
+单例主动接入 Tracker 后，记录初始化耗时、初始化错误、第一次访问的调用方和访问次数，
+通过 expvar 和 /debug/singletons 查看
+*/
+
+// Stats 某个单例的统计快照
+type Stats struct {
+	Name          string        `json:"name"`
+	Initialized   bool          `json:"initialized"`
+	InitializedAt *time.Time    `json:"initialized_at,omitempty"`
+	InitDuration  time.Duration `json:"init_duration_ns"`
+	InitErrors    int           `json:"init_errors"`
+	LastInitError string        `json:"last_init_error,omitempty"`
+	FirstCaller   string        `json:"first_caller,omitempty"`
+	Accesses      int64         `json:"accesses"`
+}
+
+// Tracker 记录某个单例的统计
+type Tracker struct {
+	accesses int64
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+var trackers = struct {
+	sync.Mutex
+	m map[string]*Tracker
+}{m: make(map[string]*Tracker)}
+
+// Track 获取名为 name 的 Tracker，同名返回同一个
+func Track(name string) *Tracker {
+	trackers.Lock()
+	defer trackers.Unlock()
+	t, ok := trackers.m[name]
+	if !ok {
+		t = &Tracker{stats: Stats{Name: name}}
+		trackers.m[name] = t
+	}
+	return t
+}
+
+// RecordInit 记录一次初始化的耗时和结果
+func (t *Tracker) RecordInit(d time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		t.stats.InitErrors++
+		t.stats.LastInitError = err.Error()
+		return
+	}
+	t.stats.Initialized = true
+	now := time.Now()
+	t.stats.InitializedAt = &now
+	t.stats.InitDuration = d
+}
+
+// Access 记录一次访问，在单例的获取函数中调用；第一次访问时记录获取函数的调用方
+func (t *Tracker) Access() {
+	if atomic.AddInt64(&t.accesses, 1) != 1 {
+		return
+	}
+	caller := callerOf(3)
+	t.mu.Lock()
+	t.stats.FirstCaller = caller
+	t.mu.Unlock()
+}
+
+// Stats 当前统计快照
+func (t *Tracker) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.stats
+	s.Accesses = atomic.LoadInt64(&t.accesses)
+	return s
+}
+
+// callerOf 跳过 skip 层调用栈后的调用方，格式为 "函数 文件:行号"
+func callerOf(skip int) string {
+	pc := make([]uintptr, 1)
+	if runtime.Callers(skip+1, pc) == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames(pc).Next()
+	return fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line)
+}
+
+// TrackInit 包装构造函数，记录初始化耗时和错误
+func TrackInit[T any](t *Tracker, fn func() (T, error)) func() (T, error) {
+	return func() (T, error) {
+		start := time.Now()
+		val, err := fn()
+		t.RecordInit(time.Since(start), err)
+		return val, err
+	}
+}
+
+// TrackInitCtx 包装带 context 的构造函数，记录初始化耗时和错误
+func TrackInitCtx[T any](t *Tracker, fn func(ctx context.Context) (T, error)) func(ctx context.Context) (T, error) {
+	return func(ctx context.Context) (T, error) {
+		start := time.Now()
+		val, err := fn(ctx)
+		t.RecordInit(time.Since(start), err)
+		return val, err
+	}
+}
+
+// Snapshot 所有 Tracker 的统计，按名字排序
+func Snapshot() []Stats {
+	trackers.Lock()
+	list := make([]*Tracker, 0, len(trackers.m))
+	for _, t := range trackers.m {
+		list = append(list, t)
+	}
+	trackers.Unlock()
+
+	stats := make([]Stats, 0, len(list))
+	for _, t := range list {
+		stats = append(stats, t.Stats())
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+var publishOnce sync.Once
+
+// PublishExpvar 把统计发布到 expvar 的 "singletons" 变量，重复调用只发布一次
+func PublishExpvar() {
+	publishOnce.Do(func() {
+		expvar.Publish("singletons", expvar.Func(func() any {
+			return Snapshot()
+		}))
+	})
+}
+
+// StatsHandler 以 JSON 输出所有单例的统计
+func StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(Snapshot())
+	})
+}
+
+// RegisterHandler 把 StatsHandler 挂到 mux 的 /debug/singletons 上
+func RegisterHandler(mux *http.ServeMux) {
+	mux.Handle("/debug/singletons", StatsHandler())
+}
@@ -0,0 +1,87 @@
+package singleton
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// trackedGetter 模拟接入 Tracker 的获取函数
+func trackedGetter(tr *Tracker, l *Lazy[int]) (int, error) {
+	tr.Access()
+	return l.Get()
+}
+
+func findStats(t *testing.T, list []Stats, name string) Stats {
+	t.Helper()
+	for _, s := range list {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("no stats for %q in %+v", name, list)
+	return Stats{}
+}
+
+func TestStatsHandler(t *testing.T) {
+	ok := Track("stats_test.ok")
+	okLazy := NewLazy(TrackInit(ok, func() (int, error) { return 1, nil }))
+	for i := 0; i < 3; i++ {
+		if _, err := trackedGetter(ok, okLazy); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bad := Track("stats_test.bad")
+	_, _ = TrackInit(bad, func() (int, error) { return 0, errors.New("dial refused") })()
+
+	mux := http.NewServeMux()
+	RegisterHandler(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/singletons", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var list []Stats
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	for i := 1; i < len(list); i++ {
+		if list[i-1].Name > list[i].Name {
+			t.Fatalf("stats not sorted by name: %q before %q", list[i-1].Name, list[i].Name)
+		}
+	}
+
+	s := findStats(t, list, "stats_test.ok")
+	if !s.Initialized || s.InitializedAt == nil || s.Accesses != 3 || s.InitErrors != 0 {
+		t.Fatalf("ok stats = %+v", s)
+	}
+	// 第一次访问的调用方是获取函数的调用方，而不是获取函数本身
+	if !strings.Contains(s.FirstCaller, "TestStatsHandler") {
+		t.Fatalf("FirstCaller = %q", s.FirstCaller)
+	}
+
+	s = findStats(t, list, "stats_test.bad")
+	if s.Initialized || s.InitErrors != 1 || s.LastInitError != "dial refused" || s.Accesses != 0 {
+		t.Fatalf("bad stats = %+v", s)
+	}
+}
+
+func TestPublishExpvar(t *testing.T) {
+	Track("stats_test.expvar")
+	PublishExpvar()
+	PublishExpvar() // 重复发布不能 panic
+
+	v := expvar.Get("singletons")
+	if v == nil {
+		t.Fatal("singletons not published")
+	}
+	var list []Stats
+	if err := json.Unmarshal([]byte(v.String()), &list); err != nil {
+		t.Fatalf("decode %s: %v", v, err)
+	}
+	findStats(t, list, "stats_test.expvar")
+}
@@ -0,0 +1,246 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/**
+基于消息目录的多语言打印机
+每种语言的文案放在 JSON 或 gettext .po 文件里，新增语言只需要新增数据文件，
+按 zh-TW -> zh -> 默认语言 的顺序回退，支持复数规则和 {name} 占位符
+*/
+
+var ErrNoMessage = errors.New("catalog: message not found")
+
+// Message 一条消息，key 为复数类别（one、few、many、other），不区分单复数的消息只有 other
+type Message map[string]string
+
+// Catalog 消息目录
+type Catalog struct {
+	defaultLocale string
+
+	mu       sync.RWMutex
+	messages map[string]map[string]Message // locale -> id -> message
+	fallback map[string]string             // 显式指定的回退语言
+	rules    map[string]pluralRule         // .po 文件头声明的复数规则
+}
+
+// New 创建消息目录，defaultLocale 为回退链最后的语言
+func New(defaultLocale string) *Catalog {
+	return &Catalog{
+		defaultLocale: Normalize(defaultLocale),
+		messages:      make(map[string]map[string]Message),
+		fallback:      make(map[string]string),
+		rules:         make(map[string]pluralRule),
+	}
+}
+
+// Normalize 统一语言标签的写法，zh_tw、zh-tw 都变成 zh-TW
+func Normalize(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(locale, "_", "-"), "-")
+	for i, p := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(p)
+		case len(p) == 2:
+			parts[i] = strings.ToUpper(p)
+		case len(p) == 4:
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:])
+		default:
+			parts[i] = strings.ToLower(p)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// Add 添加一条消息，同一个 id 会被覆盖
+func (c *Catalog) Add(locale, id string, msg Message) {
+	locale = Normalize(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	msgs, ok := c.messages[locale]
+	if !ok {
+		msgs = make(map[string]Message)
+		c.messages[locale] = msgs
+	}
+	msgs[id] = msg
+}
+
+// setRule 使用 .po 文件头声明的复数规则
+func (c *Catalog) setRule(locale string, rule pluralRule) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules[Normalize(locale)] = rule
+}
+
+// rule locale 的复数规则：文件头声明的优先，否则为内置规则
+func (c *Catalog) rule(locale string) pluralRule {
+	c.mu.RLock()
+	rule, ok := c.rules[Normalize(locale)]
+	c.mu.RUnlock()
+	if ok {
+		return rule
+	}
+	return ruleFor(locale)
+}
+
+// SetFallback 显式指定 locale 的回退语言，比如 zh-HK -> zh-TW
+func (c *Catalog) SetFallback(locale, parent string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fallback[Normalize(locale)] = Normalize(parent)
+}
+
+// Chain locale 的回退链：显式指定的回退优先，否则逐级去掉最后一段子标签，最后是默认语言
+func (c *Catalog) Chain(locale string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var chain []string
+	seen := make(map[string]bool)
+	add := func(l string) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			chain = append(chain, l)
+		}
+	}
+
+	cur := Normalize(locale)
+	for cur != "" && !seen[cur] {
+		add(cur)
+		if parent, ok := c.fallback[cur]; ok {
+			cur = parent
+		} else if i := strings.LastIndex(cur, "-"); i > 0 {
+			cur = cur[:i]
+		} else {
+			cur = ""
+		}
+	}
+	add(c.defaultLocale)
+	return chain
+}
+
+// Locales 已经加载的语言，按名字排序
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locales := make([]string, 0, len(c.messages))
+	for l := range c.messages {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// lookup 沿回退链查找消息，返回找到消息的语言
+func (c *Catalog) lookup(locale, id string) (string, Message, bool) {
+	for _, l := range c.Chain(locale) {
+		c.mu.RLock()
+		msg, ok := c.messages[l][id]
+		c.mu.RUnlock()
+		if ok {
+			return l, msg, true
+		}
+	}
+	return "", nil, false
+}
+
+// Text 取出不区分单复数的消息并替换占位符
+func (c *Catalog) Text(locale, id string, args map[string]any) (string, error) {
+	_, msg, ok := c.lookup(locale, id)
+	if !ok {
+		return "", fmt.Errorf("%w: %s (%s)", ErrNoMessage, id, locale)
+	}
+	text, ok := msg["other"]
+	if !ok {
+		return "", fmt.Errorf("%w: %s (%s) has no other form", ErrNoMessage, id, locale)
+	}
+	return interpolate(text, args), nil
+}
+
+// Plural 按 n 的复数类别取出消息并替换占位符，占位符 {count} 默认为 n
+func (c *Catalog) Plural(locale, id string, n int, args map[string]any) (string, error) {
+	found, msg, ok := c.lookup(locale, id)
+	if !ok {
+		return "", fmt.Errorf("%w: %s (%s)", ErrNoMessage, id, locale)
+	}
+	text, ok := msg[c.rule(found).category(n)]
+	if !ok {
+		if text, ok = msg["other"]; !ok {
+			return "", fmt.Errorf("%w: %s (%s) has no form for %d", ErrNoMessage, id, locale, n)
+		}
+	}
+
+	all := map[string]any{"count": n}
+	for k, v := range args {
+		all[k] = v
+	}
+	return interpolate(text, all), nil
+}
+
+// interpolate 替换 {key} 占位符，args 里没有的占位符原样保留
+func interpolate(text string, args map[string]any) string {
+	if len(args) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(text, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(text[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		key := text[start+1 : end]
+		b.WriteString(text[:start])
+		if v, ok := args[key]; ok {
+			fmt.Fprint(&b, v)
+		} else {
+			b.WriteString(text[start : end+1])
+		}
+		text = text[end+1:]
+	}
+	b.WriteString(text)
+	return b.String()
+}
+
+// LoadFS 加载 dir 目录下所有的 .json 和 .po 文件，文件名（不含扩展名）就是语言，比如 zh-TW.po
+func (c *Catalog) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		ext := path.Ext(e.Name())
+		locale := strings.TrimSuffix(e.Name(), ext)
+		var load func(string, []byte) error
+		switch ext {
+		case ".json":
+			load = c.loadJSON
+		case ".po":
+			load = c.loadPO
+		default:
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return err
+		}
+		if err := load(locale, data); err != nil {
+			return fmt.Errorf("catalog: load %s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// LoadJSON 加载一种语言的 JSON 消息，格式为
+//
+//	{
+//	  "printer": "中文打印机{name}",
+//	  "pages": {"one": "{count} page", "other": "{count} pages"}
+//	}
+func (c *Catalog) LoadJSON(locale string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return c.loadJSON(locale, data)
+}
+
+func (c *Catalog) loadJSON(locale string, data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for id, value := range raw {
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			c.Add(locale, id, Message{"other": text})
+			continue
+		}
+		var forms map[string]string
+		if err := json.Unmarshal(value, &forms); err != nil {
+			return fmt.Errorf("message %s: want string or object of plural forms", id)
+		}
+		c.Add(locale, id, Message(forms))
+	}
+	return nil
+}
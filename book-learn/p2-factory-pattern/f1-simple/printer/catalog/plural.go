@@ -0,0 +1,132 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+)
+
+/**
+复数规则，按语言的主标签选择，参考 CLDR；
+.po 文件头声明了 Plural-Forms 时以文件头为准，新增语言不需要改代码
+*/
+
+// pluralRule 返回 n 的复数类别
+type pluralRule struct {
+	categories []string // 该语言用到的类别，顺序与 .po 中 msgstr[n] 的下标一致
+	category   func(n int) string
+}
+
+var (
+	ruleOther = pluralRule{
+		categories: []string{"other"},
+		category: func(n int) string {
+			return "other"
+		},
+	}
+	ruleOneOther = pluralRule{
+		categories: []string{"one", "other"},
+		category: func(n int) string {
+			if n == 1 {
+				return "one"
+			}
+			return "other"
+		},
+	}
+	ruleFrench = pluralRule{
+		categories: []string{"one", "other"},
+		category: func(n int) string {
+			if n == 0 || n == 1 {
+				return "one"
+			}
+			return "other"
+		},
+	}
+	ruleSlavic = pluralRule{
+		categories: []string{"one", "few", "many"},
+		category: func(n int) string {
+			if n < 0 {
+				n = -n
+			}
+			switch {
+			case n%10 == 1 && n%100 != 11:
+				return "one"
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return "few"
+			default:
+				return "many"
+			}
+		},
+	}
+	rulePolish = pluralRule{
+		categories: []string{"one", "few", "many"},
+		category: func(n int) string {
+			if n < 0 {
+				n = -n
+			}
+			switch {
+			case n == 1:
+				return "one"
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return "few"
+			default:
+				return "many"
+			}
+		},
+	}
+)
+
+var pluralRules = map[string]pluralRule{
+	"zh": ruleOther,
+	"ja": ruleOther,
+	"ko": ruleOther,
+	"vi": ruleOther,
+	"th": ruleOther,
+	"en": ruleOneOther,
+	"de": ruleOneOther,
+	"nl": ruleOneOther,
+	"it": ruleOneOther,
+	"es": ruleOneOther,
+	"fr": ruleFrench,
+	"ru": ruleSlavic,
+	"uk": ruleSlavic,
+	"pl": rulePolish,
+}
+
+func ruleFor(locale string) pluralRule {
+	lang := Normalize(locale)
+	if i := strings.IndexByte(lang, '-'); i > 0 {
+		lang = lang[:i]
+	}
+	if rule, ok := pluralRules[lang]; ok {
+		return rule
+	}
+	return ruleOneOther
+}
+
+// headerRule 按 .po 文件头的 Plural-Forms 生成规则，msgstr 的下标由 plural 表达式决定。
+// 形式数和内置规则一致时沿用内置的类别名，否则按下标命名为 form0、form1……，最后一个为 other
+func headerRule(locale string, nplurals int, plural func(n int) int) pluralRule {
+	categories := ruleFor(locale).categories
+	if len(categories) != nplurals {
+		categories = make([]string, nplurals)
+		for i := range categories {
+			categories[i] = fmt.Sprintf("form%d", i)
+		}
+		categories[nplurals-1] = "other"
+	}
+	return pluralRule{
+		categories: categories,
+		category: func(n int) string {
+			i := plural(n)
+			if i < 0 || i >= len(categories) {
+				i = len(categories) - 1
+			}
+			return categories[i]
+		},
+	}
+}
+
+// PluralCategory n 在 locale 下的内置复数类别
+func PluralCategory(locale string, n int) string {
+	return ruleFor(locale).category(n)
+}
@@ -0,0 +1,261 @@
+package catalog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/**
+解析 .po 文件头里的 Plural-Forms，比如
+Plural-Forms: nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);
+plural 是 C 语言的表达式，结果就是 msgstr[n] 的下标
+*/
+
+// parsePluralForms 从文件头中取出 nplurals 和 plural，没有 Plural-Forms 时 ok 为 false
+func parsePluralForms(header string) (nplurals int, plural func(n int) int, ok bool, err error) {
+	var value string
+	for _, line := range strings.Split(header, "\n") {
+		if name, v, found := strings.Cut(line, ":"); found && strings.EqualFold(strings.TrimSpace(name), "Plural-Forms") {
+			value = v
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return 0, nil, false, nil
+	}
+
+	var expr string
+	for _, part := range strings.Split(value, ";") {
+		name, v, _ := strings.Cut(part, "=")
+		switch strings.TrimSpace(name) {
+		case "nplurals":
+			nplurals, err = strconv.Atoi(strings.TrimSpace(v))
+			if err != nil || nplurals < 1 {
+				return 0, nil, true, fmt.Errorf("invalid nplurals %q", strings.TrimSpace(v))
+			}
+		case "plural":
+			expr = v
+		}
+	}
+	if nplurals == 0 || expr == "" {
+		return 0, nil, true, fmt.Errorf("invalid Plural-Forms %q", strings.TrimSpace(value))
+	}
+	plural, err = compilePlural(expr)
+	if err != nil {
+		return 0, nil, true, err
+	}
+	return nplurals, plural, true, nil
+}
+
+// pluralParser plural 表达式的递归下降解析，按 C 语言的优先级：
+// ?: < || < && < == != < < > <= >= < + - < * / % < !
+type pluralParser struct {
+	tokens []string
+	pos    int
+}
+
+type pluralExpr func(n int) int
+
+func compilePlural(src string) (pluralExpr, error) {
+	tokens, err := tokenizePlural(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &pluralParser{tokens: tokens}
+	e, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("plural: unexpected %q", p.tokens[p.pos])
+	}
+	return e, nil
+}
+
+func tokenizePlural(src string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		case i+1 < len(src) && contains([]string{"||", "&&", "==", "!=", "<=", ">="}, src[i:i+2]):
+			tokens = append(tokens, src[i:i+2])
+			i += 2
+		case strings.IndexByte("n?:()<>+-*/%!", c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			return nil, fmt.Errorf("plural: unexpected %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func (p *pluralParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *pluralParser) ternary() (pluralExpr, error) {
+	cond, err := p.binary(0)
+	if err != nil || p.peek() != "?" {
+		return cond, err
+	}
+	p.pos++
+	yes, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != ":" {
+		return nil, fmt.Errorf("plural: missing ':'")
+	}
+	p.pos++
+	no, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	return func(n int) int {
+		if cond(n) != 0 {
+			return yes(n)
+		}
+		return no(n)
+	}, nil
+}
+
+// pluralLevels 二元运算符，优先级从低到高
+var pluralLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", ">", "<=", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *pluralParser) binary(level int) (pluralExpr, error) {
+	if level == len(pluralLevels) {
+		return p.unary()
+	}
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if !contains(pluralLevels[level], op) {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = pluralBinary(op, left, right)
+	}
+}
+
+func (p *pluralParser) unary() (pluralExpr, error) {
+	switch tok := p.peek(); {
+	case tok == "!":
+		p.pos++
+		e, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(n int) int { return boolInt(e(n) == 0) }, nil
+	case tok == "n":
+		p.pos++
+		return func(n int) int { return n }, nil
+	case tok == "(":
+		p.pos++
+		e, err := p.ternary()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("plural: missing ')'")
+		}
+		p.pos++
+		return e, nil
+	case tok != "" && tok[0] >= '0' && tok[0] <= '9':
+		p.pos++
+		v, err := strconv.Atoi(tok)
+		if err != nil {
+			return nil, fmt.Errorf("plural: %w", err)
+		}
+		return func(int) int { return v }, nil
+	case tok == "":
+		return nil, fmt.Errorf("plural: unexpected end")
+	default:
+		return nil, fmt.Errorf("plural: unexpected %q", tok)
+	}
+}
+
+func pluralBinary(op string, a, b pluralExpr) pluralExpr {
+	switch op {
+	case "||":
+		return func(n int) int { return boolInt(a(n) != 0 || b(n) != 0) }
+	case "&&":
+		return func(n int) int { return boolInt(a(n) != 0 && b(n) != 0) }
+	case "==":
+		return func(n int) int { return boolInt(a(n) == b(n)) }
+	case "!=":
+		return func(n int) int { return boolInt(a(n) != b(n)) }
+	case "<":
+		return func(n int) int { return boolInt(a(n) < b(n)) }
+	case ">":
+		return func(n int) int { return boolInt(a(n) > b(n)) }
+	case "<=":
+		return func(n int) int { return boolInt(a(n) <= b(n)) }
+	case ">=":
+		return func(n int) int { return boolInt(a(n) >= b(n)) }
+	case "+":
+		return func(n int) int { return a(n) + b(n) }
+	case "-":
+		return func(n int) int { return a(n) - b(n) }
+	case "*":
+		return func(n int) int { return a(n) * b(n) }
+	case "/":
+		return func(n int) int {
+			if d := b(n); d != 0 {
+				return a(n) / d
+			}
+			return 0
+		}
+	default: // "%"
+		return func(n int) int {
+			if d := b(n); d != 0 {
+				return a(n) % d
+			}
+			return 0
+		}
+	}
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadPO 加载一种语言的 gettext .po 文件。
+// msgstr[n] 按该语言复数规则的类别顺序对应，比如英语 msgstr[0] 为 one，msgstr[1] 为 other；
+// 文件头声明了 Plural-Forms 时按其中的 nplurals 和 plural 表达式对应；
+// 带 msgctxt 的消息 id 为 "上下文\x04msgid"；fuzzy 的条目会被忽略
+func (c *Catalog) LoadPO(locale string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return c.loadPO(locale, data)
+}
+
+// poToken .po 中的一个关键字及其字符串（已合并续行）
+type poToken struct {
+	keyword string
+	value   string
+	line    int
+}
+
+// tokenizePO 把 .po 拆成关键字，"#," 标记行的 keyword 为 "#,"，其他注释忽略
+func tokenizePO(data []byte) ([]poToken, error) {
+	var tokens []poToken
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#,"):
+			tokens = append(tokens, poToken{keyword: "#,", value: line[2:], line: lineNo})
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		var keyword, quoted string
+		if strings.HasPrefix(line, `"`) {
+			quoted = line
+		} else {
+			var ok bool
+			keyword, quoted, ok = strings.Cut(line, " ")
+			if !ok {
+				return nil, fmt.Errorf("line %d: invalid line %q", lineNo, line)
+			}
+		}
+		value, err := strconv.Unquote(strings.TrimSpace(quoted))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		if keyword == "" {
+			// 续行追加到上一个关键字
+			if len(tokens) == 0 || tokens[len(tokens)-1].keyword == "#," {
+				return nil, fmt.Errorf("line %d: unexpected string", lineNo)
+			}
+			tokens[len(tokens)-1].value += value
+			continue
+		}
+		tokens = append(tokens, poToken{keyword: keyword, value: value, line: lineNo})
+	}
+	return tokens, scanner.Err()
+}
+
+// poEntry .po 中的一个条目
+type poEntry struct {
+	ctxt   string
+	id     string
+	plural bool
+	strs   map[int]string
+	fuzzy  bool
+}
+
+func (c *Catalog) loadPO(locale string, data []byte) error {
+	tokens, err := tokenizePO(data)
+	if err != nil {
+		return err
+	}
+
+	rule := c.rule(locale)
+	entry := &poEntry{strs: make(map[int]string)}
+	flush := func(line int) error {
+		e := entry
+		entry = &poEntry{strs: make(map[int]string)}
+		if e.fuzzy {
+			return nil
+		}
+		if e.id == "" {
+			if e.ctxt != "" {
+				return nil
+			}
+			// 文件头
+			nplurals, plural, ok, err := parsePluralForms(e.strs[0])
+			if err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			if ok {
+				rule = headerRule(locale, nplurals, plural)
+				c.setRule(locale, rule)
+			}
+			return nil
+		}
+
+		msg := Message{}
+		for i, s := range e.strs {
+			if s == "" {
+				continue
+			}
+			if !e.plural {
+				msg["other"] = s
+				continue
+			}
+			if i >= len(rule.categories) {
+				return fmt.Errorf("line %d: msgstr[%d] out of range for %s", line, i, locale)
+			}
+			msg[rule.categories[i]] = s
+		}
+		if len(msg) == 0 {
+			return nil
+		}
+		id := e.id
+		if e.ctxt != "" {
+			id = e.ctxt + "\x04" + id
+		}
+		c.Add(locale, id, msg)
+		return nil
+	}
+
+	for _, tok := range tokens {
+		// msgstr 之后出现新的标记、msgctxt 或 msgid，表示上一个条目结束
+		switch tok.keyword {
+		case "#,", "msgctxt", "msgid":
+			if len(entry.strs) > 0 {
+				if err := flush(tok.line); err != nil {
+					return err
+				}
+			}
+		}
+
+		switch {
+		case tok.keyword == "#,":
+			if strings.Contains(tok.value, "fuzzy") {
+				entry.fuzzy = true
+			}
+		case tok.keyword == "msgctxt":
+			entry.ctxt = tok.value
+		case tok.keyword == "msgid":
+			entry.id = tok.value
+		case tok.keyword == "msgid_plural":
+			entry.plural = true
+		case tok.keyword == "msgstr":
+			entry.strs[0] = tok.value
+		case strings.HasPrefix(tok.keyword, "msgstr[") && strings.HasSuffix(tok.keyword, "]"):
+			n, err := strconv.Atoi(tok.keyword[len("msgstr[") : len(tok.keyword)-1])
+			if err != nil || n < 0 {
+				return fmt.Errorf("line %d: invalid %s", tok.line, tok.keyword)
+			}
+			entry.plural = true
+			entry.strs[n] = tok.value
+		default:
+			return fmt.Errorf("line %d: unknown keyword %s", tok.line, tok.keyword)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+	return flush(tokens[len(tokens)-1].line)
+}
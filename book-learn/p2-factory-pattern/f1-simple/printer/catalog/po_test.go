@@ -0,0 +1,110 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+)
+
+const polishPO = `msgid ""
+msgstr ""
+"Language: pl\n"
+"Plural-Forms: nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "pages"
+msgid_plural "pages"
+msgstr[0] "{count} strona"
+msgstr[1] "{count} strony"
+msgstr[2] "{count} stron"
+`
+
+// czechPO 没有内置规则的语言，只靠文件头就能加载
+const czechPO = `msgid ""
+msgstr ""
+"Plural-Forms: nplurals=3; plural=(n==1) ? 0 : (n>=2 && n<=4) ? 1 : 2;\n"
+
+msgid "pages"
+msgid_plural "pages"
+msgstr[0] "{count} stránka"
+msgstr[1] "{count} stránky"
+msgstr[2] "{count} stránek"
+`
+
+func TestLoadPOPluralForms(t *testing.T) {
+	tests := []struct {
+		locale string
+		po     string
+		want   map[int]string
+	}{
+		{"pl", polishPO, map[int]string{1: "1 strona", 3: "3 strony", 5: "5 stron", 12: "12 stron", 22: "22 strony", 0: "0 stron"}},
+		// 去掉文件头，使用内置的 pl 规则
+		{"pl", polishPO[strings.Index(polishPO, "\n\nmsgid")+2:], map[int]string{1: "1 strona", 24: "24 strony", 25: "25 stron"}},
+		{"cs", czechPO, map[int]string{1: "1 stránka", 4: "4 stránky", 5: "5 stránek", 0: "0 stránek"}},
+	}
+	for _, tt := range tests {
+		c := New("en")
+		if err := c.LoadPO(tt.locale, strings.NewReader(tt.po)); err != nil {
+			t.Fatalf("%s: %v", tt.locale, err)
+		}
+		for n, want := range tt.want {
+			got, err := c.Plural(tt.locale, "pages", n, nil)
+			if err != nil {
+				t.Fatalf("%s %d: %v", tt.locale, n, err)
+			}
+			if got != want {
+				t.Errorf("%s %d = %q, want %q", tt.locale, n, got, want)
+			}
+		}
+	}
+}
+
+func TestLoadPOPluralFormsErrors(t *testing.T) {
+	for _, header := range []string{
+		`"Plural-Forms: nplurals=0; plural=0;\n"`,
+		`"Plural-Forms: nplurals=2;\n"`,
+		`"Plural-Forms: nplurals=2; plural=(n==1 ? 0;\n"`,
+		`"Plural-Forms: nplurals=2; plural=n = 1;\n"`,
+	} {
+		po := "msgid \"\"\nmsgstr \"\"\n" + header + "\n\nmsgid \"a\"\nmsgstr \"b\"\n"
+		if err := New("en").LoadPO("xx", strings.NewReader(po)); err == nil {
+			t.Errorf("header %s: want error", header)
+		}
+	}
+
+	// 超出文件头声明的形式数
+	po := strings.Replace(czechPO, "nplurals=3", "nplurals=2", 1)
+	if err := New("en").LoadPO("cs", strings.NewReader(po)); err == nil || !strings.Contains(err.Error(), "msgstr[2] out of range") {
+		t.Errorf("err = %v, want msgstr[2] out of range", err)
+	}
+}
+
+func TestCompilePlural(t *testing.T) {
+	tests := []struct {
+		expr string
+		n    int
+		want int
+	}{
+		{"0", 5, 0},
+		{"n != 1", 1, 0},
+		{"n != 1", 2, 1},
+		{"n>1", 1, 0},
+		{"1 + 2 * 3", 0, 7},
+		{"(1 + 2) * 3", 0, 9},
+		{"10 - 3 - 2", 0, 5},
+		{"n % 10 == 1 && n % 100 != 11", 21, 1},
+		{"n % 10 == 1 && n % 100 != 11", 11, 0},
+		{"!n", 0, 1},
+		{"n == 0 || n == 1", 0, 1},
+		{"n==1 ? 0 : n==2 ? 1 : 2", 2, 1},
+		{"n==1 ? 0 : n==2 ? 1 : 2", 7, 2},
+		{"n / 0 + n % 0", 7, 0},
+	}
+	for _, tt := range tests {
+		e, err := compilePlural(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		if got := e(tt.n); got != tt.want {
+			t.Errorf("%s with n=%d = %d, want %d", tt.expr, tt.n, got, tt.want)
+		}
+	}
+}
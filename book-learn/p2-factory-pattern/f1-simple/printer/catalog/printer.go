@@ -0,0 +1,57 @@
+package catalog
+
+import (
+	"design-pattern-go/book-learn/p2-factory-pattern/f1-simple/printer"
+)
+
+// PrinterMessage 打印机使用的消息 id，占位符 {name} 为要打印的名字
+const PrinterMessage = "printer"
+
+// Printer 基于消息目录的打印机，实现 printer.Printer
+type Printer struct {
+	catalog *Catalog
+	locale  string
+}
+
+// Printer 创建 locale 语言的打印机
+func (c *Catalog) Printer(locale string) *Printer {
+	return &Printer{catalog: c, locale: Normalize(locale)}
+}
+
+// Locale 打印机的语言
+func (p *Printer) Locale() string {
+	return p.locale
+}
+
+// Print 打印名字，整条回退链都没有文案时原样返回名字
+func (p *Printer) Print(name string) string {
+	text, err := p.catalog.Text(p.locale, PrinterMessage, map[string]any{"name": name})
+	if err != nil {
+		return name
+	}
+	return text
+}
+
+// Text 打印任意消息
+func (p *Printer) Text(id string, args map[string]any) (string, error) {
+	return p.catalog.Text(p.locale, id, args)
+}
+
+// Plural 打印带数量的消息
+func (p *Printer) Plural(id string, n int, args map[string]any) (string, error) {
+	return p.catalog.Plural(p.locale, id, n, args)
+}
+
+// RegisterPrinters 把目录里的每种语言注册到简单工厂，kind 为 prefix + 语言，比如 "i18n:zh-TW"
+func RegisterPrinters(c *Catalog, prefix string) error {
+	for _, locale := range c.Locales() {
+		locale := locale
+		err := printer.Register(prefix+locale, func() printer.Printer {
+			return c.Printer(locale)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package cn
+
+import "design-pattern-go/book-learn/p2-factory-pattern/f1-simple/printer"
+
+func init() {
+	printer.MustRegister("cn", func() printer.Printer {
+		return new(CnPrinter)
+	})
+}
+
+// CnPrinter Chinese
+type CnPrinter struct {
+	name string
+}
+
+func (cn *CnPrinter) Print(name string) string {
+
+	return "中文打印机" + name
+}
@@ -0,0 +1,19 @@
+package en
+
+import "design-pattern-go/book-learn/p2-factory-pattern/f1-simple/printer"
+
+func init() {
+	printer.MustRegister("en", func() printer.Printer {
+		return new(EnPrinter)
+	})
+}
+
+// EnPrinter English
+type EnPrinter struct {
+	name string
+}
+
+func (en *EnPrinter) Print(name string) string {
+
+	return "英文打印机" + name
+}
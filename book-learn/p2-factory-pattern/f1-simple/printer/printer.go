@@ -0,0 +1,63 @@
+package printer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/registry"
+)
+
+/**
+简单工厂-注册表
+switch 写死了所有的打印机，未知的名字还会悄悄返回 CnPrinter，
+改成各个打印机在自己的包里注册，工厂按名字查找，找不到返回错误
+*/
+
+// Printer 简单工厂要返回的接口类型
+type Printer interface {
+	Print(name string) string
+}
+
+// Constructor 创建打印机
+type Constructor func() Printer
+
+var (
+	ErrUnknownKind   = errors.New("printer: unknown kind")
+	ErrDuplicateKind = errors.New("printer: duplicate kind")
+)
+
+var kinds = registry.NewRegistry[string, Printer]("printer",
+	registry.WithErrors(ErrUnknownKind, ErrDuplicateKind))
+
+// Register 注册一种打印机，同一个 kind 只能注册一次
+func Register(kind string, ctor Constructor) error {
+	if ctor == nil {
+		return fmt.Errorf("printer: nil constructor for %q", kind)
+	}
+	return kinds.Register(kind, registry.NoArgs(ctor))
+}
+
+// MustRegister 注册失败时 panic，一般在打印机所在包的 init 中调用
+func MustRegister(kind string, ctor Constructor) {
+	if err := Register(kind, ctor); err != nil {
+		panic(err)
+	}
+}
+
+// Alias 给已经注册的打印机起一个别名
+func Alias(alias, kind string) error {
+	return kinds.Alias(alias, kind)
+}
+
+// NewPrinter 简单工厂，kind 没有注册时返回 ErrUnknownKind
+func NewPrinter(kind string) (Printer, error) {
+	return kinds.New(kind)
+}
+
+// Kinds 已经注册的打印机，按名字排序，不含别名
+func Kinds() []string {
+	list := kinds.Keys()
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,198 @@
+package printer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"sync"
+	texttemplate "text/template"
+)
+
+/**
+打印到 io.Writer
+Printer 只能返回拼好的字符串，WriterPrinter 把打印内容交给 Renderer 渲染后写到 io.Writer，
+原来的 Printer 通过 Adapter 适配
+*/
+
+// Document 一次打印的内容
+type Document struct {
+	Kind string `json:"kind"` // 打印机种类
+	Name string `json:"name"` // 要打印的名字
+	Text string `json:"text"` // Printer.Print 的结果
+}
+
+// Renderer 把打印内容渲染到 w
+type Renderer interface {
+	Render(w io.Writer, doc Document) error
+}
+
+// RendererFunc 函数适配成 Renderer
+type RendererFunc func(w io.Writer, doc Document) error
+
+func (f RendererFunc) Render(w io.Writer, doc Document) error {
+	return f(w, doc)
+}
+
+// TextRenderer 纯文本，一行一次打印
+type TextRenderer struct{}
+
+func (r *TextRenderer) Render(w io.Writer, doc Document) error {
+	_, err := fmt.Fprintln(w, doc.Text)
+	return err
+}
+
+// JSONRenderer 每次打印输出一个 JSON 对象
+type JSONRenderer struct{}
+
+func (r *JSONRenderer) Render(w io.Writer, doc Document) error {
+	return json.NewEncoder(w).Encode(doc)
+}
+
+// HTMLRenderer 转义后的 HTML 段落
+type HTMLRenderer struct{}
+
+func (r *HTMLRenderer) Render(w io.Writer, doc Document) error {
+	_, err := fmt.Fprintf(w, "<p class=\"printer-%s\">%s</p>\n",
+		template.HTMLEscapeString(doc.Kind), template.HTMLEscapeString(doc.Text))
+	return err
+}
+
+// TemplateRenderer 用 text/template 渲染，模板的数据为 Document
+type TemplateRenderer struct {
+	tmpl *texttemplate.Template
+}
+
+// DefaultTemplate 没有指定模板时使用
+const DefaultTemplate = "{{.Text}}\n"
+
+// NewTemplateRenderer 解析模板
+func NewTemplateRenderer(text string) (*TemplateRenderer, error) {
+	tmpl, err := texttemplate.New("printer").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateRenderer{tmpl: tmpl}, nil
+}
+
+func (r *TemplateRenderer) Render(w io.Writer, doc Document) error {
+	return r.tmpl.Execute(w, doc)
+}
+
+// WriterPrinter 打印到 io.Writer 的打印机
+type WriterPrinter interface {
+	PrintTo(w io.Writer, name string) error
+}
+
+// Adapter 把只返回字符串的 Printer 适配成 WriterPrinter
+type Adapter struct {
+	kind     string
+	printer  Printer
+	renderer Renderer
+}
+
+// NewAdapter 适配 p，renderer 为空时使用纯文本
+func NewAdapter(kind string, p Printer, renderer Renderer) *Adapter {
+	if renderer == nil {
+		renderer = &TextRenderer{}
+	}
+	return &Adapter{kind: kind, printer: p, renderer: renderer}
+}
+
+func (a *Adapter) PrintTo(w io.Writer, name string) error {
+	return a.renderer.Render(w, Document{
+		Kind: a.kind,
+		Name: name,
+		Text: a.printer.Print(name),
+	})
+}
+
+// ErrReservedFormat "template" 格式由 SetTemplate 配置，不能用 RegisterFormat 注册
+var ErrReservedFormat = errors.New("printer: reserved format")
+
+// templateFormat 使用 SetTemplate 模板的格式名
+const templateFormat = "template"
+
+var (
+	formatMu  sync.RWMutex
+	formats   = map[string]Renderer{"text": &TextRenderer{}, "json": &JSONRenderer{}, "html": &HTMLRenderer{}}
+	templates = make(map[string]*TemplateRenderer) // kind -> 模板
+)
+
+// RegisterFormat 注册一种输出格式，同名会覆盖；"template" 是保留的格式名，返回 ErrReservedFormat
+func RegisterFormat(format string, r Renderer) error {
+	if format == templateFormat {
+		return fmt.Errorf("%w: %q", ErrReservedFormat, format)
+	}
+	if r == nil {
+		return fmt.Errorf("printer: nil renderer for format %q", format)
+	}
+	formatMu.Lock()
+	defer formatMu.Unlock()
+	formats[format] = r
+	return nil
+}
+
+// Formats 支持的输出格式，按名字排序；"template" 总是可用
+func Formats() []string {
+	formatMu.RLock()
+	defer formatMu.RUnlock()
+	list := []string{templateFormat}
+	for f := range formats {
+		list = append(list, f)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// SetTemplate 设置某种打印机在 "template" 格式下使用的模板
+func SetTemplate(kind, text string) error {
+	r, err := NewTemplateRenderer(text)
+	if err != nil {
+		return fmt.Errorf("printer: template for %q: %w", kind, err)
+	}
+	formatMu.Lock()
+	defer formatMu.Unlock()
+	templates[kind] = r
+	return nil
+}
+
+// NewWriterPrinter 创建 kind 打印机并按 format 渲染输出。
+// format 为空时，打印机本身实现了 WriterPrinter 就直接使用，否则按 "text" 输出；
+// format 为 "template" 时使用 SetTemplate 设置的模板，没有设置时使用 DefaultTemplate
+func NewWriterPrinter(kind, format string) (WriterPrinter, error) {
+	p, err := NewPrinter(kind)
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		if wp, ok := p.(WriterPrinter); ok {
+			return wp, nil
+		}
+		format = "text"
+	}
+	r, err := rendererFor(kind, format)
+	if err != nil {
+		return nil, err
+	}
+	return NewAdapter(kind, p, r), nil
+}
+
+func rendererFor(kind, format string) (Renderer, error) {
+	formatMu.RLock()
+	defer formatMu.RUnlock()
+
+	if format == templateFormat {
+		if t, ok := templates[kind]; ok {
+			return t, nil
+		}
+		return NewTemplateRenderer(DefaultTemplate)
+	}
+	r, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("printer: unknown format %q", format)
+	}
+	return r, nil
+}
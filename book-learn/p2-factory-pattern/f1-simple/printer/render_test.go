@@ -0,0 +1,62 @@
+package printer_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f1-simple/printer"
+	_ "design-pattern-go/book-learn/p2-factory-pattern/f1-simple/printer/cn"
+)
+
+func TestRegisterFormat(t *testing.T) {
+	upper := printer.RendererFunc(func(w io.Writer, doc printer.Document) error {
+		_, err := fmt.Fprintln(w, strings.ToUpper(doc.Kind))
+		return err
+	})
+	if err := printer.RegisterFormat("template", upper); !errors.Is(err, printer.ErrReservedFormat) {
+		t.Fatalf("err = %v, want ErrReservedFormat", err)
+	}
+	if err := printer.RegisterFormat("upper", nil); err == nil {
+		t.Fatal("nil renderer accepted")
+	}
+	if err := printer.RegisterFormat("upper", upper); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[string]int)
+	for _, f := range printer.Formats() {
+		seen[f]++
+	}
+	if seen["template"] != 1 || seen["upper"] != 1 || seen["text"] != 1 {
+		t.Fatalf("Formats = %v", printer.Formats())
+	}
+
+	wp, err := printer.NewWriterPrinter("cn", "upper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := wp.PrintTo(&buf, "张三"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "CN\n" {
+		t.Fatalf("output = %q", buf.String())
+	}
+
+	// "template" 仍然使用模板而不是注册的渲染器
+	wp, err = printer.NewWriterPrinter("cn", "template")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := wp.PrintTo(&buf, "张三"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() == "CN\n" {
+		t.Fatal("template format used the registered renderer")
+	}
+}
@@ -3,45 +3,79 @@ package main
 /**
 简单工厂
 */
-import "fmt"
+import (
+	"embed"
+	"fmt"
+	"os"
 
-type Printer interface {
-	Print(name string) string
-}
-
-// CnPrinter Chinese
-type CnPrinter struct {
-	name string
-}
+	"design-pattern-go/book-learn/p2-factory-pattern/f1-simple/printer"
+	"design-pattern-go/book-learn/p2-factory-pattern/f1-simple/printer/catalog"
+	_ "design-pattern-go/book-learn/p2-factory-pattern/f1-simple/printer/cn"
+	_ "design-pattern-go/book-learn/p2-factory-pattern/f1-simple/printer/en"
+)
 
-func (cn *CnPrinter) Print(name string) string {
+// 新增语言只需要在 locales 目录下新增文件
+//
+//go:embed locales
+var locales embed.FS
 
-	return "中文打印机" + name
-}
+func main() {
+	cat := catalog.New("en")
+	if err := cat.LoadFS(locales, "locales"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := catalog.RegisterPrinters(cat, "i18n:"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("已注册的打印机:", printer.Kinds())
 
-// EnPrinter English
-type EnPrinter struct {
-	name string
-}
+	p, err := printer.NewPrinter("en")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(p.Print("willy"))
 
-func (en *EnPrinter) Print(name string) string {
+	if _, err := printer.NewPrinter("jp"); err != nil {
+		fmt.Println(err)
+	}
 
-	return "英文打印机" + name
-}
+	// 别名和原来的名字创建同一种打印机
+	if err := printer.Alias("zh", "cn"); err != nil {
+		fmt.Println(err)
+	} else if p, err := printer.NewPrinter("zh"); err == nil {
+		fmt.Println(p.Print("willy"))
+	}
 
-// NewPrinter 简单工厂
-func NewPrinter(pName string) Printer {
-	switch pName {
-	case "cn":
-		return new(CnPrinter)
-	case "en":
-		return new(EnPrinter)
-	default:
-		return new(CnPrinter)
+	// 同一台打印机按不同格式输出到 io.Writer
+	if err := printer.SetTemplate("cn", "[{{.Kind}}] {{.Text}} ({{len .Name}} bytes)\n"); err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, format := range printer.Formats() {
+		wp, err := printer.NewWriterPrinter("cn", format)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		if err := wp.PrintTo(os.Stdout, "<willy>"); err != nil {
+			fmt.Println(err)
+		}
 	}
-}
 
-func main() {
-	printer := NewPrinter("en")
-	fmt.Println(printer.Print("willy"))
+	// zh-TW 没有 pages，回退到 zh
+	for _, locale := range []string{"zh-TW", "en", "ru", "ja"} {
+		lp := cat.Printer(locale)
+		fmt.Println(locale, cat.Chain(locale), lp.Print("willy"))
+		for _, n := range []int{1, 3, 5} {
+			text, err := lp.Plural("pages", n, map[string]any{"name": "willy"})
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			fmt.Println("  ", text)
+		}
+	}
 }
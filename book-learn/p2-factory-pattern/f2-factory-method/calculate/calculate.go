@@ -0,0 +1,191 @@
+package calculate
+
+import (
+	"errors"
+	"math"
+)
+
+/**
+工厂方法
+*/
+
+var (
+	ErrDivideByZero = errors.New("calculate: divide by zero")
+	ErrOverflow     = errors.New("calculate: overflow")
+	ErrNegativePow  = errors.New("calculate: negative exponent")
+	ErrInvalidRoot  = errors.New("calculate: invalid root")
+)
+
+// CalculateHandle 计算器（产品）接口
+// 每个计算器都需要输入两个数,计算结果
+type CalculateHandle interface {
+	SetNum1(int)
+	SetNum2(int)
+	Result() (int, error)
+}
+
+// BaseCalculate 每个计算操作都需要两个数，将这两个数抽出来
+type BaseCalculate struct {
+	num1 int
+	num2 int
+}
+
+// SetNum1 给num1赋值
+func (baseCalculate *BaseCalculate) SetNum1(num int) {
+	baseCalculate.num1 = num
+}
+
+// SetNum2 给num2赋值
+func (baseCalculate *BaseCalculate) SetNum2(num int) {
+	baseCalculate.num2 = num
+}
+
+// PlusCalculate 加法
+type PlusCalculate struct {
+	*BaseCalculate
+}
+
+func (p *PlusCalculate) Result() (int, error) {
+	return add(p.num1, p.num2)
+}
+
+// MinCalculate 减法
+type MinCalculate struct {
+	*BaseCalculate
+}
+
+func (m *MinCalculate) Result() (int, error) {
+	return sub(m.num1, m.num2)
+}
+
+// MulCalculate 乘法
+type MulCalculate struct {
+	*BaseCalculate
+}
+
+func (m *MulCalculate) Result() (int, error) {
+	return mul(m.num1, m.num2)
+}
+
+// DivCalculate 整数除法，向零取整
+type DivCalculate struct {
+	*BaseCalculate
+}
+
+func (d *DivCalculate) Result() (int, error) {
+	if d.num2 == 0 {
+		return 0, ErrDivideByZero
+	}
+	if d.num1 == math.MinInt && d.num2 == -1 {
+		return 0, ErrOverflow
+	}
+	return d.num1 / d.num2, nil
+}
+
+// ModCalculate 取余，结果的符号与 num1 相同
+type ModCalculate struct {
+	*BaseCalculate
+}
+
+func (m *ModCalculate) Result() (int, error) {
+	if m.num2 == 0 {
+		return 0, ErrDivideByZero
+	}
+	return m.num1 % m.num2, nil
+}
+
+// PowCalculate 乘方，num1 的 num2 次方
+type PowCalculate struct {
+	*BaseCalculate
+}
+
+func (p *PowCalculate) Result() (int, error) {
+	return pow(p.num1, p.num2)
+}
+
+// RootCalculate 整数开方，num2 的 num1 次方根，向零取整，比如 3 √ 27 = 3
+type RootCalculate struct {
+	*BaseCalculate
+}
+
+func (r *RootCalculate) Result() (int, error) {
+	return root(r.num1, r.num2)
+}
+
+func add(a, b int) (int, error) {
+	if (b > 0 && a > math.MaxInt-b) || (b < 0 && a < math.MinInt-b) {
+		return 0, ErrOverflow
+	}
+	return a + b, nil
+}
+
+func sub(a, b int) (int, error) {
+	if (b < 0 && a > math.MaxInt+b) || (b > 0 && a < math.MinInt+b) {
+		return 0, ErrOverflow
+	}
+	return a - b, nil
+}
+
+func mul(a, b int) (int, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	if (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return 0, ErrOverflow
+	}
+	r := a * b
+	if r/b != a {
+		return 0, ErrOverflow
+	}
+	return r, nil
+}
+
+func pow(base, exp int) (int, error) {
+	if exp < 0 {
+		return 0, ErrNegativePow
+	}
+	result := 1
+	for exp > 0 {
+		var err error
+		if exp&1 == 1 {
+			if result, err = mul(result, base); err != nil {
+				return 0, err
+			}
+		}
+		exp >>= 1
+		if exp > 0 {
+			if base, err = mul(base, base); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return result, nil
+}
+
+func root(degree, x int) (int, error) {
+	if degree <= 0 {
+		return 0, ErrInvalidRoot
+	}
+	if x < 0 {
+		if degree%2 == 0 || x == math.MinInt {
+			return 0, ErrInvalidRoot
+		}
+		r, err := root(degree, -x)
+		return -r, err
+	}
+	if degree == 1 || x < 2 {
+		return x, nil
+	}
+
+	// 二分查找最大的 r，使 r^degree <= x
+	lo, hi := 1, x
+	for lo < hi {
+		mid := lo + (hi-lo+1)/2
+		if p, err := pow(mid, degree); err == nil && p <= x {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo, nil
+}
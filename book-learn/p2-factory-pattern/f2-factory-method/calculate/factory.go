@@ -0,0 +1,150 @@
+package calculate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/registry"
+)
+
+// CalculateFactory 计算器工厂生产计算器
+type CalculateFactory interface {
+	Create() CalculateHandle
+}
+
+// PlusFactory 加法工厂结构体
+type PlusFactory struct {
+}
+
+func (p *PlusFactory) Create() CalculateHandle {
+	return &PlusCalculate{
+		BaseCalculate: &BaseCalculate{},
+	}
+}
+
+// MinFactory 减法工厂结构体
+type MinFactory struct {
+}
+
+func (m *MinFactory) Create() CalculateHandle {
+	return &MinCalculate{
+		BaseCalculate: &BaseCalculate{},
+	}
+}
+
+// MulFactory 乘法工厂结构体
+type MulFactory struct {
+}
+
+func (m *MulFactory) Create() CalculateHandle {
+	return &MulCalculate{
+		BaseCalculate: &BaseCalculate{},
+	}
+}
+
+// DivFactory 除法工厂结构体
+type DivFactory struct {
+}
+
+func (d *DivFactory) Create() CalculateHandle {
+	return &DivCalculate{
+		BaseCalculate: &BaseCalculate{},
+	}
+}
+
+// ModFactory 取余工厂结构体
+type ModFactory struct {
+}
+
+func (m *ModFactory) Create() CalculateHandle {
+	return &ModCalculate{
+		BaseCalculate: &BaseCalculate{},
+	}
+}
+
+// PowFactory 乘方工厂结构体
+type PowFactory struct {
+}
+
+func (p *PowFactory) Create() CalculateHandle {
+	return &PowCalculate{
+		BaseCalculate: &BaseCalculate{},
+	}
+}
+
+// RootFactory 开方工厂结构体
+type RootFactory struct {
+}
+
+func (r *RootFactory) Create() CalculateHandle {
+	return &RootCalculate{
+		BaseCalculate: &BaseCalculate{},
+	}
+}
+
+var (
+	ErrUnknownOp   = errors.New("calculate: unknown operator")
+	ErrDuplicateOp = errors.New("calculate: operator already registered")
+)
+
+var factories = registry.NewRegistry[string, CalculateFactory]("calculate",
+	registry.WithErrors(ErrUnknownOp, ErrDuplicateOp))
+
+func init() {
+	for _, f := range []struct {
+		op      string
+		factory CalculateFactory
+	}{
+		{"+", &PlusFactory{}},
+		{"-", &MinFactory{}},
+		{"*", &MulFactory{}},
+		{"/", &DivFactory{}},
+		{"%", &ModFactory{}},
+		{"^", &PowFactory{}},
+		{"√", &RootFactory{}},
+	} {
+		MustRegisterFactory(f.op, f.factory)
+	}
+	_ = factories.Alias("×", "*")
+	_ = factories.Alias("÷", "/")
+}
+
+// FactoryOf 按运算符或别名查找工厂
+func FactoryOf(op string) (CalculateFactory, error) {
+	return factories.New(op)
+}
+
+// RegisterFactory 注册新的运算符，已经存在的运算符返回错误
+func RegisterFactory(op string, f CalculateFactory) error {
+	if f == nil {
+		return fmt.Errorf("calculate: nil factory for %q", op)
+	}
+	return factories.Register(op, registry.NoArgs(func() CalculateFactory { return f }))
+}
+
+// MustRegisterFactory 注册失败时 panic
+func MustRegisterFactory(op string, f CalculateFactory) {
+	if err := RegisterFactory(op, f); err != nil {
+		panic(err)
+	}
+}
+
+// Ops 所有的运算符，按字典序排序，不含别名
+func Ops() []string {
+	ops := factories.Keys()
+	sort.Strings(ops)
+	return ops
+}
+
+// Calculate 按运算符计算 num1 op num2
+func Calculate(op string, num1, num2 int) (int, error) {
+	f, err := FactoryOf(op)
+	if err != nil {
+		return 0, err
+	}
+	h := f.Create()
+	h.SetNum1(num1)
+	h.SetNum2(num2)
+	return h.Result()
+}
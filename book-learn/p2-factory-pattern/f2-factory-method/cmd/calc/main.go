@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+/**
+计算器 REPL，基于工厂方法的计算器和表达式解释器
+*/
+
+func main() {
+	fmt.Println("输入 :help 查看帮助")
+	if err := NewREPL(os.Stdin, os.Stdout).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
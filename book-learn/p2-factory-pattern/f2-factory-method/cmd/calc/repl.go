@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/calculate"
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/expr"
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/numeric"
+)
+
+const helpText = `表达式       1 + 4 * (99 - x)，上一次的结果保存在 _ 中
+let x = 3    定义变量
+!n           重新执行第 n 条历史
+:history     查看历史
+:vars        查看变量
+:ops         查看已注册的运算符
+:mode        查看或切换数值类型：int、float、rat、bigint，变量按新类型转换
+:help        帮助
+:quit        退出`
+
+// mode 某种数值类型下的求值；变量以文本保存，求值时转换成当前类型，切换模式不会丢失原值
+type mode struct {
+	eval    func(node expr.Node, vars map[string]string) (string, error)
+	convert func(text string) (string, bool)
+}
+
+func modeOf[N any](arith numeric.Arith[N]) mode {
+	return mode{
+		eval: func(node expr.Node, vars map[string]string) (string, error) {
+			env := make(map[string]N, len(vars))
+			for name, text := range vars {
+				// 在当前模式下表示不了的变量视为未定义
+				if v, ok := convertValue(arith, text); ok {
+					env[name] = v
+				}
+			}
+			result, err := expr.Evaluate(node, arith, env)
+			if err != nil {
+				return "", err
+			}
+			return arith.Format(result), nil
+		},
+		convert: func(text string) (string, bool) {
+			v, ok := convertValue(arith, text)
+			if !ok {
+				return "", false
+			}
+			return arith.Format(v), true
+		},
+	}
+}
+
+// convertValue 把其他模式下得到的文本转换成 arith 的值：先直接解析，不行再经 big.Rat 转换。
+// 转成 float 可能损失精度，转成 int、bigint 要求值是整数且不溢出
+func convertValue[N any](arith numeric.Arith[N], text string) (N, bool) {
+	if v, err := arith.Parse(text); err == nil {
+		return v, true
+	}
+	var zero N
+	r, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return zero, false
+	}
+	f, _ := r.Float64()
+	for _, s := range []string{r.RatString(), strconv.FormatFloat(f, 'g', -1, 64)} {
+		if v, err := arith.Parse(s); err == nil {
+			return v, true
+		}
+	}
+	return zero, false
+}
+
+var modes = map[string]mode{
+	"int":    modeOf(numeric.Int),
+	"float":  modeOf(numeric.Float64),
+	"rat":    modeOf(numeric.Rat),
+	"bigint": modeOf(numeric.BigInt),
+}
+
+// REPL 计算器交互环境
+type REPL struct {
+	in      *bufio.Scanner
+	out     io.Writer
+	mode    string
+	vars    map[string]string
+	history []string
+}
+
+// NewREPL 创建交互环境，默认 int 模式
+func NewREPL(in io.Reader, out io.Writer) *REPL {
+	return &REPL{
+		in:   bufio.NewScanner(in),
+		out:  out,
+		mode: "int",
+		vars: make(map[string]string),
+	}
+}
+
+// Run 逐行读取并执行，直到输入结束或 :quit；单行出错只打印错误
+func (r *REPL) Run() error {
+	for {
+		fmt.Fprintf(r.out, "calc(%s)> ", r.mode)
+		if !r.in.Scan() {
+			fmt.Fprintln(r.out)
+			return r.in.Err()
+		}
+		line := strings.TrimSpace(r.in.Text())
+		if line == "" {
+			continue
+		}
+		if line == ":quit" || line == ":q" {
+			return nil
+		}
+
+		if strings.HasPrefix(line, "!") {
+			recalled, err := r.recall(line)
+			if err != nil {
+				fmt.Fprintln(r.out, "error:", err)
+				continue
+			}
+			fmt.Fprintln(r.out, recalled)
+			line = recalled
+		}
+		r.history = append(r.history, line)
+
+		if err := r.exec(line); err != nil {
+			fmt.Fprintln(r.out, "error:", err)
+		}
+	}
+}
+
+// recall 解析 !n
+func (r *REPL) recall(line string) (string, error) {
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 || n > len(r.history) {
+		return "", fmt.Errorf("no history entry %s", line)
+	}
+	return r.history[n-1], nil
+}
+
+func (r *REPL) exec(line string) error {
+	switch {
+	case strings.HasPrefix(line, ":"):
+		return r.command(line)
+	case strings.HasPrefix(line, "let "):
+		name, src, ok := strings.Cut(strings.TrimPrefix(line, "let "), "=")
+		name = strings.TrimSpace(name)
+		if !ok || !isIdent(name) {
+			return fmt.Errorf("usage: let name = expression")
+		}
+		result, err := r.eval(src)
+		if err != nil {
+			return err
+		}
+		r.vars[name] = result
+		fmt.Fprintf(r.out, "%s = %s\n", name, result)
+		return nil
+	default:
+		result, err := r.eval(line)
+		if err != nil {
+			return err
+		}
+		r.vars["_"] = result
+		fmt.Fprintln(r.out, result)
+		return nil
+	}
+}
+
+func (r *REPL) eval(src string) (string, error) {
+	node, err := expr.Parse(src)
+	if err != nil {
+		return "", err
+	}
+	return modes[r.mode].eval(node, r.vars)
+}
+
+func (r *REPL) command(line string) error {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ":help":
+		fmt.Fprintln(r.out, helpText)
+	case ":ops":
+		fmt.Fprintln(r.out, strings.Join(calculate.Ops(), " "))
+	case ":history":
+		for i, h := range r.history {
+			fmt.Fprintf(r.out, "%4d  %s\n", i+1, h)
+		}
+	case ":vars":
+		names := make([]string, 0, len(r.vars))
+		for name := range r.vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if v, ok := modes[r.mode].convert(r.vars[name]); ok {
+				fmt.Fprintf(r.out, "%s = %s\n", name, v)
+			} else {
+				fmt.Fprintf(r.out, "%s = %s (undefined in %s mode)\n", name, r.vars[name], r.mode)
+			}
+		}
+	case ":mode":
+		if len(fields) == 1 {
+			fmt.Fprintln(r.out, r.mode)
+			return nil
+		}
+		if _, ok := modes[fields[1]]; !ok {
+			return fmt.Errorf("unknown mode %s, want int|float|rat|bigint", fields[1])
+		}
+		r.mode = fields[1]
+		if names := r.undefinedVars(); len(names) > 0 {
+			fmt.Fprintf(r.out, "note: %s undefined in %s mode, switch back to use them\n", strings.Join(names, ", "), r.mode)
+		}
+	default:
+		return fmt.Errorf("unknown command %s, try :help", fields[0])
+	}
+	return nil
+}
+
+// undefinedVars 当前模式下表示不了的变量，按名字排序
+func (r *REPL) undefinedVars() []string {
+	var names []string
+	for name, text := range r.vars {
+		if _, ok := modes[r.mode].convert(text); !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isIdent(s string) bool {
+	tokens, err := expr.Tokenize(s)
+	return err == nil && len(tokens) == 2 && tokens[0].Kind == expr.Ident
+}
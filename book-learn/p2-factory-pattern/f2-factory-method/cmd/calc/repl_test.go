@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func runREPL(t *testing.T, lines ...string) string {
+	t.Helper()
+	var out strings.Builder
+	if err := NewREPL(strings.NewReader(strings.Join(lines, "\n")), &out).Run(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestModeConvertsVars(t *testing.T) {
+	out := runREPL(t,
+		"let x = 7",
+		":mode rat",
+		"let y = x / 3",
+		":mode float",
+		"y * 3",
+		":mode int",
+		"x + 1",
+		":vars",
+		":mode rat",
+		"y * 3",
+	)
+	for _, want := range []string{
+		"y = 7/3",
+		"calc(float)> 7\n", // rat 变量在 float 模式下可用
+		"note: y undefined in int mode",
+		"calc(int)> 8\n", // int 变量在 int 模式下保留原值
+		"y = 7/3 (undefined in int mode)",
+		"calc(rat)> 7\n", // 切回 rat 后 y 没有损失精度
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestModeIntegerVars(t *testing.T) {
+	// 整数值的 rat、float 变量在 int 模式下可以使用，超出 int 范围时只有 bigint 可用
+	out := runREPL(t,
+		":mode rat",
+		"let a = 6 / 3",
+		":mode float",
+		"let b = 10 ^ 30",
+		":mode int",
+		"a * 2",
+		":mode bigint",
+		"b + 1",
+	)
+	for _, want := range []string{
+		"note: b undefined in int mode",
+		"calc(int)> 4\n",
+		"calc(bigint)> 1000000000000000000000000000001\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
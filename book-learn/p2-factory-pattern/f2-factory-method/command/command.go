@@ -0,0 +1,107 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/calculate"
+)
+
+/**
+命令模式-可撤销的计算器
+每次运算包装成命令对象，累加器 = 累加器 op 操作数，
+执行前记录原来的值，撤销时直接恢复，除法、取余这类不可逆的运算也能撤销
+*/
+
+var (
+	ErrNothingToUndo = errors.New("command: nothing to undo")
+	ErrNothingToRedo = errors.New("command: nothing to redo")
+	ErrNotExecuted   = errors.New("command: not executed")
+)
+
+// Command 作用在累加器上的命令
+type Command interface {
+	Execute(acc *int) error
+	Undo(acc *int) error
+}
+
+// OpCommand 一次运算：acc = acc Op Operand，运算由 calculate 包的工厂创建的计算器完成
+type OpCommand struct {
+	Op      string
+	Operand int
+
+	factory  calculate.CalculateFactory
+	prev     int
+	executed bool
+}
+
+// NewOpCommand 创建运算命令，运算符没有注册时返回错误
+func NewOpCommand(op string, operand int) (*OpCommand, error) {
+	factory, err := calculate.FactoryOf(op)
+	if err != nil {
+		return nil, err
+	}
+	return &OpCommand{Op: op, Operand: operand, factory: factory}, nil
+}
+
+func (c *OpCommand) Execute(acc *int) error {
+	handle := c.factory.Create()
+	handle.SetNum1(*acc)
+	handle.SetNum2(c.Operand)
+	result, err := handle.Result()
+	if err != nil {
+		return err
+	}
+	c.prev = *acc
+	c.executed = true
+	*acc = result
+	return nil
+}
+
+func (c *OpCommand) Undo(acc *int) error {
+	if !c.executed {
+		return ErrNotExecuted
+	}
+	*acc = c.prev
+	c.executed = false
+	return nil
+}
+
+func (c *OpCommand) String() string {
+	return fmt.Sprintf("%s %d", c.Op, c.Operand)
+}
+
+// MacroCommand 一组命令，作为一个整体执行和撤销
+type MacroCommand struct {
+	Name  string
+	Steps []Command
+
+	done int // 已经执行成功的步数
+}
+
+// Execute 依次执行，某一步失败时撤销已经执行的步骤，累加器保持不变
+func (m *MacroCommand) Execute(acc *int) error {
+	m.done = 0
+	for i, step := range m.Steps {
+		if err := step.Execute(acc); err != nil {
+			_ = m.Undo(acc)
+			return fmt.Errorf("macro %s step %d: %w", m.Name, i+1, err)
+		}
+		m.done++
+	}
+	return nil
+}
+
+// Undo 逆序撤销
+func (m *MacroCommand) Undo(acc *int) error {
+	for ; m.done > 0; m.done-- {
+		if err := m.Steps[m.done-1].Undo(acc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MacroCommand) String() string {
+	return "macro " + m.Name
+}
@@ -0,0 +1,111 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/calculate"
+)
+
+func TestMacroFailureRollsBack(t *testing.T) {
+	s := NewSession(WithValue(5))
+	if err := s.StartMacro("bad"); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []struct {
+		op      string
+		operand int
+	}{{"+", 1}, {"*", 2}, {"-", 12}} {
+		if err := s.Apply(step.op, step.operand); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.StopMacro(); err != nil {
+		t.Fatal(err)
+	}
+	// 录制时最后一步把累加器减到 0，回放时第 3 步 除 0 失败
+	if s.Value() != 0 {
+		t.Fatalf("value = %d, want 0", s.Value())
+	}
+	s.macros["bad"][2] = &OpCommand{Op: "/", Operand: 0}
+	if err := s.Apply("+", 7); err != nil {
+		t.Fatal(err)
+	}
+	undo := s.CanUndo()
+
+	err := s.Replay("bad")
+	if !errors.Is(err, calculate.ErrDivideByZero) {
+		t.Fatalf("err = %v, want ErrDivideByZero", err)
+	}
+	if !strings.Contains(err.Error(), "step 3") {
+		t.Fatalf("err = %v, want it to report step 3", err)
+	}
+	if s.Value() != 7 {
+		t.Fatalf("value = %d after rollback, want 7", s.Value())
+	}
+	if s.CanUndo() != undo {
+		t.Fatalf("failed macro was pushed onto the undo stack")
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	s := NewSession(WithMaxDepth(2))
+	for _, n := range []int{1, 2, 3} {
+		if err := s.Apply("+", n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Apply("/", 4); err != nil {
+		t.Fatal(err)
+	}
+	if s.Value() != 1 || s.CanUndo() != 2 {
+		t.Fatalf("value %d, undo %d", s.Value(), s.CanUndo())
+	}
+	if err := s.Undo(); err != nil || s.Value() != 6 {
+		t.Fatalf("undo: %v, value %d", err, s.Value())
+	}
+	if err := s.Undo(); err != nil || s.Value() != 3 {
+		t.Fatalf("undo: %v, value %d", err, s.Value())
+	}
+	if err := s.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("err = %v, want ErrNothingToUndo", err)
+	}
+	if err := s.Redo(); err != nil || s.Value() != 6 {
+		t.Fatalf("redo: %v, value %d", err, s.Value())
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	s := NewSession()
+	_ = s.StartMacro("m")
+	_ = s.Apply("+", 10)
+	_ = s.Apply("%", 3)
+	_ = s.StopMacro()
+	_ = s.Replay("m")
+	_ = s.Undo()
+
+	var buf bytes.Buffer
+	if err := s.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Value() != s.Value() || r.CanUndo() != s.CanUndo() || r.CanRedo() != s.CanRedo() {
+		t.Fatalf("restored %d/%d/%d, want %d/%d/%d", r.Value(), r.CanUndo(), r.CanRedo(), s.Value(), s.CanUndo(), s.CanRedo())
+	}
+	if err := r.Redo(); err != nil || r.Value() != 11%3 {
+		t.Fatalf("redo: %v, value %d", err, r.Value())
+	}
+	for r.CanUndo() > 0 {
+		if err := r.Undo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.Value() != 0 {
+		t.Fatalf("value = %d after undoing everything, want 0", r.Value())
+	}
+}
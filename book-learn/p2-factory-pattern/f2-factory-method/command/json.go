@@ -0,0 +1,163 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/**
+会话的 JSON 格式
+撤销栈和重做栈里的命令连同执行前的值一起保存，恢复后可以继续撤销和重做
+*/
+
+// commandJSON 一条命令，Macro 不为空时为宏命令
+type commandJSON struct {
+	Op      string        `json:"op,omitempty"`
+	Operand int           `json:"operand,omitempty"`
+	Prev    int           `json:"prev,omitempty"`
+	Macro   string        `json:"macro,omitempty"`
+	Steps   []commandJSON `json:"steps,omitempty"`
+}
+
+type stepJSON struct {
+	Op      string `json:"op"`
+	Operand int    `json:"operand"`
+}
+
+type sessionJSON struct {
+	Value    int                   `json:"value"`
+	MaxDepth int                   `json:"max_depth"`
+	Undo     []commandJSON         `json:"undo"`
+	Redo     []commandJSON         `json:"redo"`
+	Macros   map[string][]stepJSON `json:"macros,omitempty"`
+}
+
+// MarshalJSON 保存累加器的值、撤销栈、重做栈和宏；正在录制的宏不保存
+func (s *Session) MarshalJSON() ([]byte, error) {
+	out := sessionJSON{
+		Value:    s.value,
+		MaxDepth: s.maxDepth,
+		Undo:     []commandJSON{},
+		Redo:     []commandJSON{},
+	}
+	for _, cmd := range s.undo {
+		c, err := encodeCommand(cmd)
+		if err != nil {
+			return nil, err
+		}
+		out.Undo = append(out.Undo, c)
+	}
+	for _, cmd := range s.redo {
+		c, err := encodeCommand(cmd)
+		if err != nil {
+			return nil, err
+		}
+		out.Redo = append(out.Redo, c)
+	}
+	if len(s.macros) > 0 {
+		out.Macros = make(map[string][]stepJSON, len(s.macros))
+		for name, steps := range s.macros {
+			list := make([]stepJSON, 0, len(steps))
+			for _, step := range steps {
+				list = append(list, stepJSON{Op: step.Op, Operand: step.Operand})
+			}
+			out.Macros[name] = list
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON 恢复会话，运算符没有注册时返回错误
+func (s *Session) UnmarshalJSON(data []byte) error {
+	var in sessionJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	restored := NewSession(WithMaxDepth(in.MaxDepth), WithValue(in.Value))
+	for _, c := range in.Undo {
+		cmd, err := decodeCommand(c, true)
+		if err != nil {
+			return err
+		}
+		restored.undo = append(restored.undo, cmd)
+	}
+	for _, c := range in.Redo {
+		cmd, err := decodeCommand(c, false)
+		if err != nil {
+			return err
+		}
+		restored.redo = append(restored.redo, cmd)
+	}
+	for name, list := range in.Macros {
+		steps := make([]*OpCommand, 0, len(list))
+		for _, step := range list {
+			cmd, err := NewOpCommand(step.Op, step.Operand)
+			if err != nil {
+				return fmt.Errorf("macro %s: %w", name, err)
+			}
+			steps = append(steps, cmd)
+		}
+		restored.macros[name] = steps
+	}
+	*s = *restored
+	return nil
+}
+
+// Save 以 JSON 保存会话
+func (s *Session) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(s)
+}
+
+// Load 从 JSON 恢复会话
+func Load(r io.Reader) (*Session, error) {
+	s := NewSession()
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func encodeCommand(cmd Command) (commandJSON, error) {
+	switch c := cmd.(type) {
+	case *OpCommand:
+		return commandJSON{Op: c.Op, Operand: c.Operand, Prev: c.prev}, nil
+	case *MacroCommand:
+		out := commandJSON{Macro: c.Name}
+		for _, step := range c.Steps {
+			s, err := encodeCommand(step)
+			if err != nil {
+				return commandJSON{}, err
+			}
+			out.Steps = append(out.Steps, s)
+		}
+		return out, nil
+	default:
+		return commandJSON{}, fmt.Errorf("command: cannot marshal %T", cmd)
+	}
+}
+
+// decodeCommand 还原命令，executed 为 true 时命令处于已执行状态，可以直接撤销
+func decodeCommand(c commandJSON, executed bool) (Command, error) {
+	if c.Macro != "" {
+		macro := &MacroCommand{Name: c.Macro}
+		for _, s := range c.Steps {
+			step, err := decodeCommand(s, executed)
+			if err != nil {
+				return nil, err
+			}
+			macro.Steps = append(macro.Steps, step)
+		}
+		if executed {
+			macro.done = len(macro.Steps)
+		}
+		return macro, nil
+	}
+	cmd, err := NewOpCommand(c.Op, c.Operand)
+	if err != nil {
+		return nil, err
+	}
+	cmd.prev = c.Prev
+	cmd.executed = executed
+	return cmd, nil
+}
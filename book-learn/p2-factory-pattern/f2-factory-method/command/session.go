@@ -0,0 +1,188 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+)
+
+const defaultMaxDepth = 100
+
+// SessionOption 设置 Session 的参数
+type SessionOption func(s *Session)
+
+// WithMaxDepth 撤销栈的最大深度，超出时丢弃最早的命令，<=0 使用默认值
+func WithMaxDepth(n int) SessionOption {
+	return func(s *Session) {
+		if n > 0 {
+			s.maxDepth = n
+		}
+	}
+}
+
+// WithValue 累加器的初始值
+func WithValue(v int) SessionOption {
+	return func(s *Session) {
+		s.value = v
+	}
+}
+
+// Session 累加器会话，维护撤销栈、重做栈和录制的宏
+type Session struct {
+	value    int
+	maxDepth int
+	undo     []Command
+	redo     []Command
+
+	macros    map[string][]*OpCommand
+	recording string
+	recorded  []*OpCommand
+}
+
+// NewSession 创建会话
+func NewSession(opts ...SessionOption) *Session {
+	s := &Session{
+		maxDepth: defaultMaxDepth,
+		macros:   make(map[string][]*OpCommand),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Value 累加器当前的值
+func (s *Session) Value() int {
+	return s.value
+}
+
+// Apply 执行一次运算，正在录制宏时同时录进宏里
+func (s *Session) Apply(op string, operand int) error {
+	cmd, err := NewOpCommand(op, operand)
+	if err != nil {
+		return err
+	}
+	if err := s.Do(cmd); err != nil {
+		return err
+	}
+	if s.recording != "" {
+		step, _ := NewOpCommand(op, operand)
+		s.recorded = append(s.recorded, step)
+	}
+	return nil
+}
+
+// Do 执行命令并压入撤销栈，清空重做栈
+func (s *Session) Do(cmd Command) error {
+	if err := cmd.Execute(&s.value); err != nil {
+		return err
+	}
+	s.push(cmd)
+	s.redo = nil
+	return nil
+}
+
+func (s *Session) push(cmd Command) {
+	s.undo = append(s.undo, cmd)
+	if over := len(s.undo) - s.maxDepth; over > 0 {
+		s.undo = append(s.undo[:0:0], s.undo[over:]...)
+	}
+}
+
+// Undo 撤销最近一次命令
+func (s *Session) Undo() error {
+	n := len(s.undo)
+	if n == 0 {
+		return ErrNothingToUndo
+	}
+	cmd := s.undo[n-1]
+	if err := cmd.Undo(&s.value); err != nil {
+		return err
+	}
+	s.undo = s.undo[:n-1]
+	s.redo = append(s.redo, cmd)
+	return nil
+}
+
+// Redo 重做最近一次撤销的命令
+func (s *Session) Redo() error {
+	n := len(s.redo)
+	if n == 0 {
+		return ErrNothingToRedo
+	}
+	cmd := s.redo[n-1]
+	if err := cmd.Execute(&s.value); err != nil {
+		return err
+	}
+	s.redo = s.redo[:n-1]
+	s.push(cmd)
+	return nil
+}
+
+// CanUndo 撤销栈中的命令数
+func (s *Session) CanUndo() int {
+	return len(s.undo)
+}
+
+// CanRedo 重做栈中的命令数
+func (s *Session) CanRedo() int {
+	return len(s.redo)
+}
+
+// StartMacro 开始录制宏，之后 Apply 的运算都会录进去
+func (s *Session) StartMacro(name string) error {
+	if s.recording != "" {
+		return fmt.Errorf("command: already recording macro %s", s.recording)
+	}
+	if name == "" {
+		return fmt.Errorf("command: empty macro name")
+	}
+	s.recording = name
+	s.recorded = nil
+	return nil
+}
+
+// StopMacro 结束录制并保存宏，同名的宏会被覆盖
+func (s *Session) StopMacro() error {
+	if s.recording == "" {
+		return fmt.Errorf("command: not recording")
+	}
+	s.macros[s.recording] = s.recorded
+	s.recording = ""
+	s.recorded = nil
+	return nil
+}
+
+// Replay 回放宏，整个宏作为一条命令撤销
+func (s *Session) Replay(name string) error {
+	steps, ok := s.macros[name]
+	if !ok {
+		return fmt.Errorf("command: unknown macro %s", name)
+	}
+	macro, err := newMacro(name, steps)
+	if err != nil {
+		return err
+	}
+	return s.Do(macro)
+}
+
+func newMacro(name string, steps []*OpCommand) (*MacroCommand, error) {
+	macro := &MacroCommand{Name: name}
+	for _, step := range steps {
+		cmd, err := NewOpCommand(step.Op, step.Operand)
+		if err != nil {
+			return nil, err
+		}
+		macro.Steps = append(macro.Steps, cmd)
+	}
+	return macro, nil
+}
+
+// Macros 已经录制的宏，按名字排序
+func (s *Session) Macros() []string {
+	names := make([]string, 0, len(s.macros))
+	for name := range s.macros {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,141 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/calculate"
+)
+
+// Env 变量绑定
+type Env map[string]int
+
+// EvalError 求值错误，比如除零、溢出、未定义的变量
+type EvalError struct {
+	Pos Pos
+	Err error
+}
+
+func (e *EvalError) Error() string {
+	return fmt.Sprintf("eval error at %s: %v", e.Pos, e.Err)
+}
+
+func (e *EvalError) Unwrap() error {
+	return e.Err
+}
+
+// Node 语法树节点
+type Node interface {
+	Eval(env Env) (int, error)
+	Position() Pos
+	String() string
+}
+
+// Num 数字字面量，保存原始文本，求值时再按数值类型解析
+type Num struct {
+	Text string
+	Pos  Pos
+}
+
+func (n *Num) Eval(env Env) (int, error) {
+	v, err := strconv.Atoi(n.Text)
+	if err != nil {
+		return 0, &EvalError{Pos: n.Pos, Err: fmt.Errorf("invalid integer %s", n.Text)}
+	}
+	return v, nil
+}
+
+func (n *Num) Position() Pos {
+	return n.Pos
+}
+
+func (n *Num) String() string {
+	return n.Text
+}
+
+// Var 变量
+type Var struct {
+	Name string
+	Pos  Pos
+}
+
+func (v *Var) Eval(env Env) (int, error) {
+	val, ok := env[v.Name]
+	if !ok {
+		return 0, &EvalError{Pos: v.Pos, Err: fmt.Errorf("undefined variable %s", v.Name)}
+	}
+	return val, nil
+}
+
+func (v *Var) Position() Pos {
+	return v.Pos
+}
+
+func (v *Var) String() string {
+	return v.Name
+}
+
+// Neg 取负，按 0 - x 交给减法计算器，溢出同样会报错
+type Neg struct {
+	X   Node
+	Pos Pos
+}
+
+func (n *Neg) Eval(env Env) (int, error) {
+	x, err := n.X.Eval(env)
+	if err != nil {
+		return 0, err
+	}
+	return apply("-", 0, x, n.Pos)
+}
+
+func (n *Neg) Position() Pos {
+	return n.Pos
+}
+
+func (n *Neg) String() string {
+	return "(-" + n.X.String() + ")"
+}
+
+// Binary 二元运算，运算由 calculate.FactoryOf(Op) 创建的计算器完成
+type Binary struct {
+	Op    string
+	Left  Node
+	Right Node
+	Pos   Pos // 运算符的位置
+}
+
+func (b *Binary) Eval(env Env) (int, error) {
+	left, err := b.Left.Eval(env)
+	if err != nil {
+		return 0, err
+	}
+	right, err := b.Right.Eval(env)
+	if err != nil {
+		return 0, err
+	}
+	return apply(b.Op, left, right, b.Pos)
+}
+
+func (b *Binary) Position() Pos {
+	return b.Pos
+}
+
+func (b *Binary) String() string {
+	return "(" + b.Left.String() + " " + b.Op + " " + b.Right.String() + ")"
+}
+
+func apply(op string, num1, num2 int, pos Pos) (int, error) {
+	factory, err := calculate.FactoryOf(op)
+	if err != nil {
+		return 0, &EvalError{Pos: pos, Err: err}
+	}
+	handle := factory.Create()
+	handle.SetNum1(num1)
+	handle.SetNum2(num2)
+	result, err := handle.Result()
+	if err != nil {
+		return 0, &EvalError{Pos: pos, Err: err}
+	}
+	return result, nil
+}
@@ -0,0 +1,136 @@
+package expr_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/expr"
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/numeric"
+)
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		src  string
+		tree string
+		want int
+	}{
+		{"1 + 2 * 3", "(1 + (2 * 3))", 7},
+		{"(1 + 2) * 3", "((1 + 2) * 3)", 9},
+		{"2 - 3 - 4", "((2 - 3) - 4)", -5},
+		{"24 / 4 / 2", "((24 / 4) / 2)", 3},
+		{"17 % 5 * 2", "((17 % 5) * 2)", 4},
+		{"2 ^ 3 ^ 2", "(2 ^ (3 ^ 2))", 512},
+		{"(2 ^ 3) ^ 2", "((2 ^ 3) ^ 2)", 64},
+		{"-2 ^ 2", "(-(2 ^ 2))", -4},
+		{"(-2) ^ 2", "((-2) ^ 2)", 4},
+		{"-2 * 3", "((-2) * 3)", -6},
+		{"--3", "(-(-3))", 3},
+		{"2 - -3", "(2 - (-3))", 5},
+		{"-(3 - 5)", "(-(3 - 5))", 2},
+		{"1 + 2 √ 9 * 2", "(1 + ((2 √ 9) * 2))", 7},
+		{"((7))", "7", 7},
+		{"x * (y + 1)", "(x * (y + 1))", 12},
+	}
+	env := expr.Env{"x": 3, "y": 3}
+	for _, tt := range tests {
+		node, err := expr.Parse(tt.src)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.src, err)
+		}
+		if got := node.String(); got != tt.tree {
+			t.Errorf("Parse(%q) = %s, want %s", tt.src, got, tt.tree)
+		}
+		if got, err := node.Eval(env); err != nil || got != tt.want {
+			t.Errorf("Eval(%q) = %d, %v, want %d", tt.src, got, err, tt.want)
+		}
+	}
+}
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	tests := []struct {
+		src  string
+		line int
+		col  int
+		msg  string
+	}{
+		{"1 +", 1, 4, "unexpected end of input"},
+		{"1 + * 2", 1, 5, `unexpected`},
+		{"(1 + 2", 1, 7, "expected ')' to close '(' at line 1, col 1"},
+		{"1 2", 1, 3, "unexpected"},
+		{"1 $ 2", 1, 3, "unexpected character '$'"},
+		{"1 +\n  * 2", 2, 3, "unexpected"},
+		{"√ + 1", 1, 1, "unexpected"},
+		{"中文 + )", 1, 6, "unexpected"}, // 列按字符计算
+	}
+	for _, tt := range tests {
+		_, err := expr.Parse(tt.src)
+		var syntaxErr *expr.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q) err = %v, want *SyntaxError", tt.src, err)
+			continue
+		}
+		if syntaxErr.Pos != (expr.Pos{Line: tt.line, Col: tt.col}) {
+			t.Errorf("Parse(%q) at %s, want line %d, col %d", tt.src, syntaxErr.Pos, tt.line, tt.col)
+		}
+		if !strings.Contains(err.Error(), tt.msg) {
+			t.Errorf("Parse(%q) err = %q, want it to contain %q", tt.src, err, tt.msg)
+		}
+	}
+}
+
+func TestEvalErrorPosition(t *testing.T) {
+	tests := []struct {
+		src  string
+		col  int
+		want error
+	}{
+		{"1 + x", 5, nil},
+		{"8 / (2 - 2)", 3, numeric.ErrDivideByZero},
+		{"2 ^ -1", 3, numeric.ErrNegativePow},
+	}
+	for _, tt := range tests {
+		_, err := expr.Eval(tt.src, nil)
+		var evalErr *expr.EvalError
+		if !errors.As(err, &evalErr) {
+			t.Fatalf("Eval(%q) err = %v, want *EvalError", tt.src, err)
+		}
+		if evalErr.Pos != (expr.Pos{Line: 1, Col: tt.col}) {
+			t.Errorf("Eval(%q) at %s, want col %d", tt.src, evalErr.Pos, tt.col)
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("Eval(%q) err = %v, want %v", tt.src, err, tt.want)
+		}
+	}
+}
+
+func TestDivideByZeroEachMode(t *testing.T) {
+	t.Run("int", func(t *testing.T) { checkDivideByZero(t, numeric.Int) })
+	t.Run("float", func(t *testing.T) { checkDivideByZero(t, numeric.Float64) })
+	t.Run("rat", func(t *testing.T) { checkDivideByZero(t, numeric.Rat) })
+	t.Run("bigint", func(t *testing.T) { checkDivideByZero(t, numeric.BigInt) })
+}
+
+func checkDivideByZero[N any](t *testing.T, arith numeric.Arith[N]) {
+	t.Helper()
+	for _, src := range []string{"7 / (3 - 3)", "7 % (3 - 3)"} {
+		node, err := expr.Parse(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = expr.Evaluate(node, arith, nil)
+		if !errors.Is(err, numeric.ErrDivideByZero) {
+			t.Errorf("%s: err = %v, want ErrDivideByZero", src, err)
+		}
+		var evalErr *expr.EvalError
+		if !errors.As(err, &evalErr) || evalErr.Pos.Col != 3 {
+			t.Errorf("%s: err = %v, want *EvalError at col 3", src, err)
+		}
+	}
+	// 同样的表达式除数不为零时可以算出结果
+	node, _ := expr.Parse("7 / (3 - 2)")
+	got, err := expr.Evaluate(node, arith, nil)
+	if err != nil || arith.Format(got) != "7" {
+		t.Errorf("7 / (3 - 2) = %s, %v", arith.Format(got), err)
+	}
+}
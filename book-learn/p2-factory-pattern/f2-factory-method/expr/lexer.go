@@ -0,0 +1,133 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+/**
+解释器模式-表达式语言
+把 "1 + 4 * (99 - x)" 解析成语法树，每个二元运算节点交给计算器工厂创建的计算器计算
+*/
+
+// Pos 源码中的位置，行列都从 1 开始，列按字符计算
+type Pos struct {
+	Line int
+	Col  int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("line %d, col %d", p.Line, p.Col)
+}
+
+// SyntaxError 语法错误
+type SyntaxError struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at %s: %s", e.Pos, e.Msg)
+}
+
+// TokenKind 词法单元的种类
+type TokenKind int
+
+const (
+	EOF TokenKind = iota
+	Number
+	Ident
+	Operator
+	LParen
+	RParen
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case EOF:
+		return "end of input"
+	case Number:
+		return "number"
+	case Ident:
+		return "identifier"
+	case Operator:
+		return "operator"
+	case LParen:
+		return "'('"
+	case RParen:
+		return "')'"
+	default:
+		return fmt.Sprintf("TokenKind(%d)", int(k))
+	}
+}
+
+// Token 词法单元
+type Token struct {
+	Kind TokenKind
+	Text string
+	Pos  Pos
+}
+
+// operators 支持的运算符，和 calculate 包里注册的运算符对应
+const operators = "+-*/%^√"
+
+// Tokenize 把源码拆成词法单元，最后一个总是 EOF
+func Tokenize(src string) ([]Token, error) {
+	var tokens []Token
+	runes := []rune(src)
+	pos := Pos{Line: 1, Col: 1}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := pos
+		switch {
+		case r == '\n':
+			pos.Line++
+			pos.Col = 1
+			i++
+			continue
+		case unicode.IsSpace(r):
+			pos.Col++
+			i++
+			continue
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			// 小数部分，int 模式下求值时会报错，float、rat 模式可以使用
+			if j+1 < len(runes) && runes[j] == '.' && unicode.IsDigit(runes[j+1]) {
+				j++
+				for j < len(runes) && unicode.IsDigit(runes[j]) {
+					j++
+				}
+			}
+			tokens = append(tokens, Token{Kind: Number, Text: string(runes[i:j]), Pos: start})
+			pos.Col += j - i
+			i = j
+			continue
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, Token{Kind: Ident, Text: string(runes[i:j]), Pos: start})
+			pos.Col += j - i
+			i = j
+			continue
+		case r == '(':
+			tokens = append(tokens, Token{Kind: LParen, Text: "(", Pos: start})
+		case r == ')':
+			tokens = append(tokens, Token{Kind: RParen, Text: ")", Pos: start})
+		case strings.ContainsRune(operators, r):
+			tokens = append(tokens, Token{Kind: Operator, Text: string(r), Pos: start})
+		default:
+			return nil, &SyntaxError{Pos: start, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+		pos.Col++
+		i++
+	}
+	tokens = append(tokens, Token{Kind: EOF, Pos: pos})
+	return tokens, nil
+}
@@ -0,0 +1,57 @@
+package expr
+
+import (
+	"fmt"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/numeric"
+)
+
+// Evaluate 按 arith 的数值类型计算语法树，二元运算交给 numeric.FactoryOf 创建的计算器
+func Evaluate[N any](node Node, arith numeric.Arith[N], env map[string]N) (N, error) {
+	var zero N
+	switch n := node.(type) {
+	case *Num:
+		v, err := arith.Parse(n.Text)
+		if err != nil {
+			return zero, &EvalError{Pos: n.Pos, Err: fmt.Errorf("invalid %s number %s", arith.Name(), n.Text)}
+		}
+		return v, nil
+	case *Var:
+		v, ok := env[n.Name]
+		if !ok {
+			return zero, &EvalError{Pos: n.Pos, Err: fmt.Errorf("undefined variable %s", n.Name)}
+		}
+		return v, nil
+	case *Neg:
+		x, err := Evaluate(n.X, arith, env)
+		if err != nil {
+			return zero, err
+		}
+		// 0 - x，借用 Parse 得到各类型的零值
+		z, err := arith.Parse("0")
+		if err != nil {
+			return zero, err
+		}
+		return applyNumeric(arith, "-", z, x, n.Pos)
+	case *Binary:
+		left, err := Evaluate(n.Left, arith, env)
+		if err != nil {
+			return zero, err
+		}
+		right, err := Evaluate(n.Right, arith, env)
+		if err != nil {
+			return zero, err
+		}
+		return applyNumeric(arith, n.Op, left, right, n.Pos)
+	default:
+		return zero, fmt.Errorf("expr: unknown node %T", node)
+	}
+}
+
+func applyNumeric[N any](arith numeric.Arith[N], op string, num1, num2 N, pos Pos) (N, error) {
+	result, err := numeric.Calc(arith, op, num1, num2)
+	if err != nil {
+		return result, &EvalError{Pos: pos, Err: err}
+	}
+	return result, nil
+}
@@ -0,0 +1,136 @@
+package expr
+
+import (
+	"fmt"
+)
+
+// 运算符优先级，数字越大结合越紧；取负的优先级介于 √ 和 ^ 之间，-2^2 = -(2^2)
+var precedence = map[string]int{
+	"+": 1,
+	"-": 1,
+	"*": 2,
+	"/": 2,
+	"%": 2,
+	"√": 3,
+	"^": 5,
+}
+
+const negPrecedence = 4
+
+// rightAssoc 右结合的运算符，2^3^2 = 2^(3^2)
+var rightAssoc = map[string]bool{
+	"^": true,
+}
+
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+// Parse 把源码解析成语法树
+func Parse(src string) (Node, error) {
+	tokens, err := Tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	node, err := p.parseExpr(1)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.Kind != EOF {
+		return nil, p.unexpected(tok)
+	}
+	return node, nil
+}
+
+// Eval 解析并计算表达式
+func Eval(src string, env Env) (int, error) {
+	node, err := Parse(src)
+	if err != nil {
+		return 0, err
+	}
+	return node.Eval(env)
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() Token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != EOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) unexpected(tok Token) error {
+	if tok.Kind == EOF {
+		return &SyntaxError{Pos: tok.Pos, Msg: "unexpected end of input"}
+	}
+	return &SyntaxError{Pos: tok.Pos, Msg: fmt.Sprintf("unexpected %s %q", tok.Kind, tok.Text)}
+}
+
+// parseExpr 优先级爬升：解析由优先级不低于 minPrec 的二元运算符连接的表达式
+func (p *parser) parseExpr(minPrec int) (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.Kind != Operator {
+			return left, nil
+		}
+		prec := precedence[tok.Text]
+		if prec < minPrec {
+			return left, nil
+		}
+		p.next()
+
+		nextMin := prec + 1
+		if rightAssoc[tok.Text] {
+			nextMin = prec
+		}
+		right, err := p.parseExpr(nextMin)
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: tok.Text, Left: left, Right: right, Pos: tok.Pos}
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.peek()
+	if tok.Kind == Operator && tok.Text == "-" {
+		p.next()
+		x, err := p.parseExpr(negPrecedence)
+		if err != nil {
+			return nil, err
+		}
+		return &Neg{X: x, Pos: tok.Pos}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+	switch tok.Kind {
+	case Number:
+		return &Num{Text: tok.Text, Pos: tok.Pos}, nil
+	case Ident:
+		return &Var{Name: tok.Text, Pos: tok.Pos}, nil
+	case LParen:
+		node, err := p.parseExpr(1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.Kind != RParen {
+			return nil, &SyntaxError{Pos: closing.Pos, Msg: fmt.Sprintf("expected ')' to close '(' at %s", tok.Pos)}
+		}
+		return node, nil
+	default:
+		return nil, p.unexpected(tok)
+	}
+}
@@ -1,88 +1,105 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/calculate"
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/command"
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/expr"
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/numeric"
+)
 
 /**
 工厂方法
 */
 
-//CalculateHandle 计算器（产品）接口
-//每个计算器都需要输入两个数,计算结果
-type CalculateHandle interface {
-	setNum1(int)
-	setNum2(int)
-	calResult() int
-}
-
-//BaseCalculate 每个计算操作都需要两个数，将这两个数抽出来
-type BaseCalculate struct {
-	num1 int
-	num2 int
-}
-
-//PlusCalculate 加法
-type PlusCalculate struct {
-	*BaseCalculate
-}
-
-// MinCalculate 减法
-type MinCalculate struct {
-	*BaseCalculate
-}
-
-//给num1 num2赋值
-func (baseCalculate *BaseCalculate) setNum1(num int) {
-	baseCalculate.num1 = num
-}
-
-func (baseCalculate *BaseCalculate) setNum2(num int) {
-	baseCalculate.num2 = num
-}
-
-func (p *PlusCalculate) calResult() int {
-	return p.num1 + p.num2
-}
-
-func (m *MinCalculate) calResult() int {
-	return m.num1 - m.num2
-}
-
-//CalculateFactory 计算器工厂生产计算器
-type CalculateFactory interface {
-	Create() CalculateHandle
-}
-
-//PlusFactory 加法工厂结构体
-type PlusFactory struct {
-}
+func main() {
+	var factory calculate.CalculateFactory
+	factory = &calculate.PlusFactory{}
+	plusOp := factory.Create()
+	plusOp.SetNum1(1)
+	plusOp.SetNum2(4)
+	result, _ := plusOp.Result()
+	fmt.Printf("加法计算结果:%d\n", result)
 
-func (p *PlusFactory) Create() CalculateHandle {
-	return &PlusCalculate{
-		BaseCalculate: &BaseCalculate{},
+	factory = &calculate.MinFactory{}
+	MinOp := factory.Create()
+	MinOp.SetNum1(99)
+	MinOp.SetNum2(1)
+	result, _ = MinOp.Result()
+	fmt.Printf("减法计算结果:%d\n", result)
+
+	// 按运算符查找工厂
+	cases := []struct {
+		op         string
+		num1, num2 int
+	}{
+		{"*", 6, 7},
+		{"/", 7, 0},
+		{"%", 17, 5},
+		{"^", 2, 10},
+		{"^", 2, 64},
+		{"√", 3, 27},
+		{"+", math.MaxInt, 1},
+		{"&", 1, 1},
+	}
+	for _, c := range cases {
+		result, err := calculate.Calculate(c.op, c.num1, c.num2)
+		if err != nil {
+			fmt.Printf("%d %s %d 计算失败:%v\n", c.num1, c.op, c.num2, err)
+			continue
+		}
+		fmt.Printf("%d %s %d = %d\n", c.num1, c.op, c.num2, result)
 	}
-}
-
-//MinFactory 减法工厂结构体
-type MinFactory struct {
-}
 
-func (m *MinFactory) Create() CalculateHandle {
-	return &MinCalculate{
-		BaseCalculate: &BaseCalculate{},
+	// 表达式：每个二元运算交给对应的工厂创建的计算器
+	env := expr.Env{"x": 9}
+	for _, src := range []string{"1 + 4 * (99 - x)", "-2 ^ 2 + 3 √ 27", "10 / (x - 9)", "1 +\n  * 2"} {
+		node, err := expr.Parse(src)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		result, err := node.Eval(env)
+		if err != nil {
+			fmt.Printf("%s 计算失败:%v\n", node, err)
+			continue
+		}
+		fmt.Printf("%s = %d\n", node, result)
 	}
-}
 
-func main() {
-	var factory CalculateFactory
-	factory = &PlusFactory{}
-	plusOp := factory.Create()
-	plusOp.setNum1(1)
-	plusOp.setNum2(4)
-	fmt.Printf("加法计算结果:%d\n", plusOp.calResult())
+	// 泛型计算器：同样的工厂方法结构用于不同的数值类型
+	if r, err := numeric.Calc(numeric.Float64, "+", 0.1, 0.2); err == nil {
+		fmt.Printf("float: 0.1 + 0.2 = %s\n", numeric.Float64.Format(r))
+	}
+	if r, err := numeric.Calc(numeric.BigInt, "^", big.NewInt(2), big.NewInt(100)); err == nil {
+		fmt.Printf("bigint: 2 ^ 100 = %s\n", numeric.BigInt.Format(r))
+	}
+	if r, err := numeric.Calc(numeric.Rat, "/", big.NewRat(1, 3), big.NewRat(2, 1)); err == nil {
+		fmt.Printf("rat: 1/3 / 2 = %s\n", numeric.Rat.Format(r))
+	}
+	if _, err := numeric.Calc(numeric.Rat, "√", big.NewRat(2, 1), big.NewRat(2, 1)); err != nil {
+		fmt.Printf("rat: 2 √ 2 计算失败:%v\n", err)
+	}
 
-	factory = &MinFactory{}
-	MinOp := factory.Create()
-	MinOp.setNum1(99)
-	MinOp.setNum2(1)
-	fmt.Printf("减法计算结果:%d\n", MinOp.calResult())
+	// 命令模式：累加器上的运算可以撤销、重做，宏可以录制和回放
+	session := command.NewSession(command.WithMaxDepth(10))
+	_ = session.Apply("+", 10)
+	_ = session.StartMacro("double-plus-one")
+	_ = session.Apply("*", 2)
+	_ = session.Apply("+", 1)
+	_ = session.StopMacro()
+	_ = session.Replay("double-plus-one")
+	fmt.Printf("session: %d\n", session.Value())
+	_ = session.Undo()
+	fmt.Printf("session undo macro: %d\n", session.Value())
+	_ = session.Redo()
+	fmt.Printf("session redo macro: %d\n", session.Value())
+	if err := session.Apply("/", 0); err != nil {
+		fmt.Printf("session: %v\n", err)
+	}
+	_ = session.Save(os.Stdout)
 }
@@ -0,0 +1,119 @@
+package numeric
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// MaxBigExp *big.Int 和 *big.Rat 乘方允许的最大指数，避免一次运算耗尽内存
+const MaxBigExp = 1 << 16
+
+// bigIntArith *big.Int 运算，任意精度不会溢出：
+// 除法和取余与 int 一致，向零取整（Quo/Rem）；指数超过 MaxBigExp 返回 ErrOverflow；
+// 每次运算都返回新的 *big.Int，不会修改操作数
+type bigIntArith struct{}
+
+// BigInt *big.Int 运算
+var BigInt Arith[*big.Int] = bigIntArith{}
+
+func (bigIntArith) Name() string {
+	return "bigint"
+}
+
+func (bigIntArith) Add(a, b *big.Int) (*big.Int, error) {
+	return new(big.Int).Add(a, b), nil
+}
+
+func (bigIntArith) Sub(a, b *big.Int) (*big.Int, error) {
+	return new(big.Int).Sub(a, b), nil
+}
+
+func (bigIntArith) Mul(a, b *big.Int) (*big.Int, error) {
+	return new(big.Int).Mul(a, b), nil
+}
+
+func (bigIntArith) Div(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivideByZero
+	}
+	return new(big.Int).Quo(a, b), nil
+}
+
+func (bigIntArith) Mod(a, b *big.Int) (*big.Int, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivideByZero
+	}
+	return new(big.Int).Rem(a, b), nil
+}
+
+func (bigIntArith) Pow(base, exp *big.Int) (*big.Int, error) {
+	if exp.Sign() < 0 {
+		return nil, ErrNegativePow
+	}
+	if exp.Cmp(big.NewInt(MaxBigExp)) > 0 {
+		return nil, ErrOverflow
+	}
+	return new(big.Int).Exp(base, exp, nil), nil
+}
+
+// Root 整数开方，向零取整；次数不小于 x 的位数时结果为 1，不受 MaxBigExp 限制
+func (bigIntArith) Root(degree, x *big.Int) (*big.Int, error) {
+	if degree.Sign() <= 0 || !degree.IsInt64() {
+		return nil, ErrInvalidRoot
+	}
+	n := degree.Int64()
+	if x.Sign() < 0 {
+		if n%2 == 0 {
+			return nil, ErrInvalidRoot
+		}
+		r, err := bigIntArith{}.Root(degree, new(big.Int).Neg(x))
+		if err != nil {
+			return nil, err
+		}
+		return r.Neg(r), nil
+	}
+	if n == 2 {
+		return new(big.Int).Sqrt(x), nil
+	}
+	return bigRoot(x, n), nil
+}
+
+// bigRoot 二分查找最大的 r，使 r^n <= x
+func bigRoot(x *big.Int, n int64) *big.Int {
+	if x.Sign() == 0 || n == 1 {
+		return new(big.Int).Set(x)
+	}
+	// x < 2^BitLen <= 2^n，结果只能是 1；不提前返回的话次数很大时 Exp 会一直算下去
+	if n >= int64(x.BitLen()) {
+		return big.NewInt(1)
+	}
+	one := big.NewInt(1)
+	lo := big.NewInt(1)
+	hi := new(big.Int).Lsh(one, uint(int64(x.BitLen())/n+1))
+	p := new(big.Int)
+	mid := new(big.Int)
+	bigN := big.NewInt(n)
+	for lo.Cmp(hi) < 0 {
+		mid.Add(lo, hi)
+		mid.Add(mid, one)
+		mid.Rsh(mid, 1)
+		if p.Exp(mid, bigN, nil).Cmp(x) <= 0 {
+			lo.Set(mid)
+		} else {
+			hi.Sub(mid, one)
+		}
+	}
+	return lo
+}
+
+func (bigIntArith) Parse(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("numeric: invalid integer %q", s)
+	}
+	return n, nil
+}
+
+func (bigIntArith) Format(n *big.Int) string {
+	return n.String()
+}
@@ -0,0 +1,96 @@
+package numeric
+
+import (
+	"math"
+	"strconv"
+)
+
+// floatArith float64 运算，遵循 IEEE 754：
+// 结果会有舍入误差（0.1 + 0.2 = 0.30000000000000004）；
+// 有限的操作数得到 ±Inf 时返回 ErrOverflow，除数为 0 返回 ErrDivideByZero 而不是 Inf；
+// 结果为 NaN（比如负数开偶次方）时返回 ErrInvalidRoot 或 ErrInvalid
+type floatArith struct{}
+
+// Float64 float64 运算
+var Float64 Arith[float64] = floatArith{}
+
+func (floatArith) Name() string {
+	return "float"
+}
+
+func checkFloat(a, b, r float64) (float64, error) {
+	if math.IsNaN(r) {
+		return 0, ErrInvalid
+	}
+	if math.IsInf(r, 0) && !math.IsInf(a, 0) && !math.IsInf(b, 0) {
+		return 0, ErrOverflow
+	}
+	return r, nil
+}
+
+func (floatArith) Add(a, b float64) (float64, error) {
+	return checkFloat(a, b, a+b)
+}
+
+func (floatArith) Sub(a, b float64) (float64, error) {
+	return checkFloat(a, b, a-b)
+}
+
+func (floatArith) Mul(a, b float64) (float64, error) {
+	return checkFloat(a, b, a*b)
+}
+
+func (floatArith) Div(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, ErrDivideByZero
+	}
+	return checkFloat(a, b, a/b)
+}
+
+func (floatArith) Mod(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, ErrDivideByZero
+	}
+	return checkFloat(a, b, math.Mod(a, b))
+}
+
+func (floatArith) Pow(base, exp float64) (float64, error) {
+	if base == 0 && exp < 0 {
+		return 0, ErrDivideByZero
+	}
+	return checkFloat(base, exp, math.Pow(base, exp))
+}
+
+// Root 奇数次方根允许负数，比如 3 √ -8 = -2
+func (floatArith) Root(degree, x float64) (float64, error) {
+	if degree == 0 {
+		return 0, ErrInvalidRoot
+	}
+	if x < 0 {
+		if degree != math.Trunc(degree) || math.Mod(degree, 2) == 0 {
+			return 0, ErrInvalidRoot
+		}
+		return -floatRoot(degree, -x), nil
+	}
+	return checkFloat(degree, x, floatRoot(degree, x))
+}
+
+// floatRoot 平方根和立方根用专门的函数，精度比 Pow(x, 1/n) 高
+func floatRoot(degree, x float64) float64 {
+	switch degree {
+	case 2:
+		return math.Sqrt(x)
+	case 3:
+		return math.Cbrt(x)
+	default:
+		return math.Pow(x, 1/degree)
+	}
+}
+
+func (floatArith) Parse(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+func (floatArith) Format(n float64) string {
+	return strconv.FormatFloat(n, 'g', -1, 64)
+}
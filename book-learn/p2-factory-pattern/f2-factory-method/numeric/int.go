@@ -0,0 +1,54 @@
+package numeric
+
+import (
+	"strconv"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/calculate"
+)
+
+// intArith int 运算，直接使用 calculate 包的工厂：
+// 超出 int 范围返回 ErrOverflow，除法向零取整，负指数返回 ErrNegativePow
+type intArith struct{}
+
+// Int int 运算
+var Int Arith[int] = intArith{}
+
+func (intArith) Name() string {
+	return "int"
+}
+
+func (intArith) Add(a, b int) (int, error) {
+	return calculate.Calculate("+", a, b)
+}
+
+func (intArith) Sub(a, b int) (int, error) {
+	return calculate.Calculate("-", a, b)
+}
+
+func (intArith) Mul(a, b int) (int, error) {
+	return calculate.Calculate("*", a, b)
+}
+
+func (intArith) Div(a, b int) (int, error) {
+	return calculate.Calculate("/", a, b)
+}
+
+func (intArith) Mod(a, b int) (int, error) {
+	return calculate.Calculate("%", a, b)
+}
+
+func (intArith) Pow(base, exp int) (int, error) {
+	return calculate.Calculate("^", base, exp)
+}
+
+func (intArith) Root(degree, x int) (int, error) {
+	return calculate.Calculate("√", degree, x)
+}
+
+func (intArith) Parse(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+func (intArith) Format(n int) string {
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,163 @@
+package numeric
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/calculate"
+	"design-pattern-go/book-learn/p2-factory-pattern/registry"
+)
+
+/**
+泛型工厂方法
+calculate 包只能算 int，这里把计算器和工厂按数值类型参数化，
+同样的结构可以用于 int、float64、*big.Int 和 *big.Rat
+*/
+
+var (
+	ErrUnsupported = errors.New("numeric: operation not supported")
+	ErrInexact     = errors.New("numeric: result is not exact")
+	ErrInvalid     = errors.New("numeric: invalid operand")
+)
+
+// Arith 某种数值类型的运算，错误的含义与 calculate 包一致
+type Arith[N any] interface {
+	Name() string
+	Add(a, b N) (N, error)
+	Sub(a, b N) (N, error)
+	Mul(a, b N) (N, error)
+	Div(a, b N) (N, error)
+	Mod(a, b N) (N, error)
+	Pow(base, exp N) (N, error)
+	Root(degree, x N) (N, error)
+	Parse(s string) (N, error)
+	Format(n N) string
+}
+
+// CalculateHandle 计算器（产品）接口
+type CalculateHandle[N any] interface {
+	SetNum1(N)
+	SetNum2(N)
+	Result() (N, error)
+}
+
+// CalculateFactory 计算器工厂
+type CalculateFactory[N any] interface {
+	Create() CalculateHandle[N]
+}
+
+// BaseCalculate 每个计算操作都需要两个数，将这两个数抽出来
+type BaseCalculate[N any] struct {
+	num1 N
+	num2 N
+}
+
+func (b *BaseCalculate[N]) SetNum1(num N) {
+	b.num1 = num
+}
+
+func (b *BaseCalculate[N]) SetNum2(num N) {
+	b.num2 = num
+}
+
+// Calculate 某个运算的计算器
+type Calculate[N any] struct {
+	*BaseCalculate[N]
+	op func(a, b N) (N, error)
+}
+
+func (c *Calculate[N]) Result() (N, error) {
+	return c.op(c.num1, c.num2)
+}
+
+// Factory 某个运算的工厂
+type Factory[N any] struct {
+	op func(a, b N) (N, error)
+}
+
+func (f *Factory[N]) Create() CalculateHandle[N] {
+	return &Calculate[N]{
+		BaseCalculate: &BaseCalculate[N]{},
+		op:            f.op,
+	}
+}
+
+// registries 每个 arith 的运算符注册表，只创建一次，弃用提示等状态才能跨调用保留
+var registries = struct {
+	sync.Mutex
+	m map[any]any
+}{m: make(map[any]any)}
+
+// operations arith 类型的运算符注册表；arith 不能作为 map 的键时每次新建
+func operations[N any](arith Arith[N]) *registry.Registry[string, CalculateFactory[N]] {
+	if !reflect.TypeOf(arith).Comparable() {
+		return newOperations(arith)
+	}
+	registries.Lock()
+	defer registries.Unlock()
+	if r, ok := registries.m[arith]; ok {
+		return r.(*registry.Registry[string, CalculateFactory[N]])
+	}
+	r := newOperations(arith)
+	registries.m[arith] = r
+	return r
+}
+
+// newOperations 创建运算符注册表，别名与 calculate 一致
+func newOperations[N any](arith Arith[N]) *registry.Registry[string, CalculateFactory[N]] {
+	r := registry.NewRegistry[string, CalculateFactory[N]]("numeric", registry.WithErrors(ErrUnknownOp, nil))
+	for _, op := range []struct {
+		op string
+		fn func(a, b N) (N, error)
+	}{
+		{"+", arith.Add},
+		{"-", arith.Sub},
+		{"*", arith.Mul},
+		{"/", arith.Div},
+		{"%", arith.Mod},
+		{"^", arith.Pow},
+		{"√", arith.Root},
+	} {
+		f := &Factory[N]{op: op.fn}
+		r.MustRegister(op.op, registry.NoArgs(func() CalculateFactory[N] { return f }))
+	}
+	_ = r.Alias("×", "*")
+	_ = r.Alias("÷", "/")
+	return r
+}
+
+// FactoryOf 按运算符或别名查找 arith 类型的工厂
+func FactoryOf[N any](arith Arith[N], op string) (CalculateFactory[N], error) {
+	return operations(arith).New(op)
+}
+
+// Ops 支持的运算符，与 calculate.Ops 一致
+func Ops() []string {
+	ops := operations[int](Int).Keys()
+	sort.Strings(ops)
+	return ops
+}
+
+// Calc 按运算符计算 num1 op num2
+func Calc[N any](arith Arith[N], op string, num1, num2 N) (N, error) {
+	f, err := FactoryOf(arith, op)
+	if err != nil {
+		var zero N
+		return zero, err
+	}
+	h := f.Create()
+	h.SetNum1(num1)
+	h.SetNum2(num2)
+	return h.Result()
+}
+
+// 复用 calculate 包的错误，调用方用 errors.Is 判断即可，不用关心数值类型
+var (
+	ErrDivideByZero = calculate.ErrDivideByZero
+	ErrOverflow     = calculate.ErrOverflow
+	ErrNegativePow  = calculate.ErrNegativePow
+	ErrInvalidRoot  = calculate.ErrInvalidRoot
+	ErrUnknownOp    = calculate.ErrUnknownOp
+)
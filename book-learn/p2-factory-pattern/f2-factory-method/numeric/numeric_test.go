@@ -0,0 +1,154 @@
+package numeric_test
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f2-factory-method/numeric"
+)
+
+// calcCase 一次计算，want 为空时期望返回 err
+type calcCase struct {
+	a, op, b string
+	want     string
+	err      error
+}
+
+func runCases[N any](t *testing.T, arith numeric.Arith[N], cases []calcCase) {
+	t.Helper()
+	for _, c := range cases {
+		c := c
+		t.Run(c.a+c.op+c.b, func(t *testing.T) {
+			a, err := arith.Parse(c.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := arith.Parse(c.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := numeric.Calc(arith, c.op, a, b)
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Fatalf("%s %s %s: err = %v, want %v", c.a, c.op, c.b, err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s %s %s: %v", c.a, c.op, c.b, err)
+			}
+			if s := arith.Format(got); s != c.want {
+				t.Fatalf("%s %s %s = %s, want %s", c.a, c.op, c.b, s, c.want)
+			}
+		})
+	}
+}
+
+func TestInt(t *testing.T) {
+	maxInt := big.NewInt(math.MaxInt).String()
+	minInt := big.NewInt(math.MinInt).String()
+	runCases[int](t, numeric.Int, []calcCase{
+		{a: "7", op: "/", b: "2", want: "3"},
+		{a: "-7", op: "%", b: "2", want: "-1"},
+		{a: maxInt, op: "+", b: "1", err: numeric.ErrOverflow},
+		{a: minInt, op: "-", b: "1", err: numeric.ErrOverflow},
+		{a: minInt, op: "*", b: "-1", err: numeric.ErrOverflow},
+		{a: "2", op: "^", b: "64", err: numeric.ErrOverflow},
+		{a: "2", op: "^", b: "-1", err: numeric.ErrNegativePow},
+		{a: "1", op: "/", b: "0", err: numeric.ErrDivideByZero},
+		{a: "3", op: "√", b: "-27", want: "-3"},
+		{a: "2", op: "√", b: "-4", err: numeric.ErrInvalidRoot},
+		{a: "0", op: "√", b: "4", err: numeric.ErrInvalidRoot},
+		{a: "1000000000000", op: "√", b: "8", want: "1"},
+	})
+}
+
+func TestFloat64(t *testing.T) {
+	runCases[float64](t, numeric.Float64, []calcCase{
+		{a: "0.1", op: "+", b: "0.2", want: "0.30000000000000004"},
+		{a: "3", op: "√", b: "27", want: "3"},
+		{a: "3", op: "√", b: "-8", want: "-2"},
+		{a: "1e308", op: "*", b: "10", err: numeric.ErrOverflow},
+		{a: "10", op: "^", b: "400", err: numeric.ErrOverflow},
+		{a: "1", op: "/", b: "0", err: numeric.ErrDivideByZero},
+		{a: "2", op: "√", b: "-4", err: numeric.ErrInvalidRoot},
+		{a: "0", op: "√", b: "4", err: numeric.ErrInvalidRoot},
+		{a: "-8", op: "^", b: "0.5", err: numeric.ErrInvalid},
+	})
+}
+
+func TestBigInt(t *testing.T) {
+	runCases[*big.Int](t, numeric.BigInt, []calcCase{
+		{a: "2", op: "^", b: "100", want: "1267650600228229401496703205376"},
+		{a: "9223372036854775807", op: "+", b: "1", want: "9223372036854775808"},
+		{a: "2", op: "^", b: "65537", err: numeric.ErrOverflow},
+		{a: "2", op: "^", b: "-1", err: numeric.ErrNegativePow},
+		{a: "-7", op: "/", b: "2", want: "-3"},
+		{a: "1", op: "%", b: "0", err: numeric.ErrDivideByZero},
+		{a: "3", op: "√", b: "1000000000000000000000000000000", want: "10000000000"},
+		{a: "3", op: "√", b: "-27", want: "-3"},
+		{a: "2", op: "√", b: "-4", err: numeric.ErrInvalidRoot},
+		{a: "0", op: "√", b: "4", err: numeric.ErrInvalidRoot},
+		{a: "1000000000000", op: "√", b: "8", want: "1"},
+		{a: "1000000000000", op: "√", b: "-8", err: numeric.ErrInvalidRoot},
+		{a: "1000000000001", op: "√", b: "-8", want: "-1"},
+	})
+}
+
+func TestRat(t *testing.T) {
+	runCases[*big.Rat](t, numeric.Rat, []calcCase{
+		{a: "1/3", op: "+", b: "1/6", want: "1/2"},
+		{a: "0.1", op: "+", b: "0.2", want: "3/10"},
+		{a: "2/3", op: "^", b: "-2", want: "9/4"},
+		{a: "2", op: "^", b: "1/2", err: numeric.ErrInexact},
+		{a: "2", op: "^", b: "65537", err: numeric.ErrOverflow},
+		{a: "0", op: "^", b: "-1", err: numeric.ErrDivideByZero},
+		{a: "2", op: "√", b: "4/9", want: "2/3"},
+		{a: "2", op: "√", b: "2", err: numeric.ErrInexact},
+		{a: "1/2", op: "√", b: "4", err: numeric.ErrInexact},
+		{a: "2", op: "√", b: "-4", err: numeric.ErrInvalidRoot},
+		{a: "1000000000000", op: "√", b: "8", err: numeric.ErrInexact},
+		{a: "1000000000000", op: "√", b: "1", want: "1"},
+	})
+}
+
+// TestRootLargeDegree 次数很大时开方要马上返回
+func TestRootLargeDegree(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = numeric.Calc(numeric.Rat, "√", big.NewRat(1e12, 1), big.NewRat(8, 1))
+		_, _ = numeric.Calc(numeric.BigInt, "√", big.NewInt(1e12), new(big.Int).Lsh(big.NewInt(1), 4096))
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("root with a large degree did not return")
+	}
+}
+
+// TestFactoryOfCached 注册表每个 Arith 只建一次，重复查找拿到同一个工厂
+func TestFactoryOfCached(t *testing.T) {
+	first, err := numeric.FactoryOf(numeric.Rat, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan numeric.CalculateFactory[*big.Rat])
+	for i := 0; i < 8; i++ {
+		go func() {
+			f, err := numeric.FactoryOf(numeric.Rat, "×")
+			if err != nil {
+				t.Error(err)
+			}
+			done <- f
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		if f := <-done; f != first {
+			t.Fatal("FactoryOf returned a new factory")
+		}
+	}
+}
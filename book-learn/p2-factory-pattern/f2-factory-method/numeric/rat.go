@@ -0,0 +1,112 @@
+package numeric
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ratArith *big.Rat 运算，结果是精确的有理数：
+// 除法不丢精度（1/3 就是 1/3）；取余定义为 a - b*trunc(a/b)；
+// 指数必须是整数，可以是负数；开方只有结果也是有理数时才成功，否则返回 ErrInexact
+type ratArith struct{}
+
+// Rat *big.Rat 运算
+var Rat Arith[*big.Rat] = ratArith{}
+
+func (ratArith) Name() string {
+	return "rat"
+}
+
+func (ratArith) Add(a, b *big.Rat) (*big.Rat, error) {
+	return new(big.Rat).Add(a, b), nil
+}
+
+func (ratArith) Sub(a, b *big.Rat) (*big.Rat, error) {
+	return new(big.Rat).Sub(a, b), nil
+}
+
+func (ratArith) Mul(a, b *big.Rat) (*big.Rat, error) {
+	return new(big.Rat).Mul(a, b), nil
+}
+
+func (ratArith) Div(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivideByZero
+	}
+	return new(big.Rat).Quo(a, b), nil
+}
+
+func (ratArith) Mod(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, ErrDivideByZero
+	}
+	q := new(big.Rat).Quo(a, b)
+	trunc := new(big.Int).Quo(q.Num(), q.Denom())
+	r := new(big.Rat).Mul(b, new(big.Rat).SetInt(trunc))
+	return r.Sub(a, r), nil
+}
+
+func (ratArith) Pow(base, exp *big.Rat) (*big.Rat, error) {
+	if !exp.IsInt() {
+		return nil, ErrInexact
+	}
+	e := exp.Num()
+	if new(big.Int).Abs(e).Cmp(big.NewInt(MaxBigExp)) > 0 {
+		return nil, ErrOverflow
+	}
+	if e.Sign() < 0 && base.Sign() == 0 {
+		return nil, ErrDivideByZero
+	}
+	abs := new(big.Int).Abs(e)
+	num := new(big.Int).Exp(base.Num(), abs, nil)
+	den := new(big.Int).Exp(base.Denom(), abs, nil)
+	if e.Sign() < 0 {
+		num, den = den, num
+	}
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+// Root 分子分母都能开尽时返回精确结果，比如 2 √ 4/9 = 2/3
+func (ratArith) Root(degree, x *big.Rat) (*big.Rat, error) {
+	if !degree.IsInt() {
+		return nil, ErrInexact
+	}
+	d := degree.Num()
+	num, err := exactRoot(d, x.Num())
+	if err != nil {
+		return nil, err
+	}
+	den, err := exactRoot(d, x.Denom())
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+func exactRoot(degree, x *big.Int) (*big.Int, error) {
+	r, err := BigInt.Root(degree, x)
+	if err != nil {
+		return nil, err
+	}
+	if new(big.Int).Exp(r, degree, nil).Cmp(x) != 0 {
+		return nil, ErrInexact
+	}
+	return r, nil
+}
+
+// Parse 支持 "3"、"1/3"、"0.25" 这几种写法
+func (ratArith) Parse(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("numeric: invalid rational %q", s)
+	}
+	return r, nil
+}
+
+// Format 整数不带分母
+func (ratArith) Format(n *big.Rat) string {
+	if n.IsInt() {
+		return n.Num().String()
+	}
+	return n.RatString()
+}
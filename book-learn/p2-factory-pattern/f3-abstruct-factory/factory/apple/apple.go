@@ -0,0 +1,65 @@
+package apple
+
+import "design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory"
+
+const brand = "apple"
+
+var (
+	watchModels = []string{"Apple Watch Series 9", "Apple Watch Ultra 2"}
+	phoneModels = []string{"iPhone 15", "iPhone 15 Pro"}
+)
+
+const (
+	watchFirmware = "watchOS 10.0"
+	phoneFirmware = "iOS 17.0"
+)
+
+func init() {
+	factory.MustRegister(brand, func(cfg *factory.Config) factory.AbstractFactory {
+		return &AppleFactory{cfg: cfg}
+	})
+}
+
+// AppleFactory 苹果手机制造商可以制造手机和手表
+type AppleFactory struct {
+	cfg *factory.Config
+}
+
+func (apple *AppleFactory) CreateWatch(model string) (factory.IWatch, error) {
+	model, err := factory.PickModel(brand, watchModels, model)
+	if err != nil {
+		return nil, err
+	}
+	watch := &AppleWatch{BaseDevice: apple.cfg.NewDevice(brand, model, watchFirmware)}
+	if err := apple.cfg.Manufacture(watch); err != nil {
+		return nil, err
+	}
+	return watch, nil
+}
+func (apple *AppleFactory) CreatePhone(model string) (factory.ICallPhone, error) {
+	model, err := factory.PickModel(brand, phoneModels, model)
+	if err != nil {
+		return nil, err
+	}
+	phone := &IPhone{BaseDevice: apple.cfg.NewDevice(brand, model, phoneFirmware)}
+	if err := apple.cfg.Manufacture(phone); err != nil {
+		return nil, err
+	}
+	return phone, nil
+}
+
+type AppleWatch struct {
+	*factory.BaseDevice
+}
+
+func (watch *AppleWatch) WatchTime() {
+	watch.Logger().Printf("%s Apple Watch看时间", watch)
+}
+
+type IPhone struct {
+	*factory.BaseDevice
+}
+
+func (phone *IPhone) CallSomebody() {
+	phone.Logger().Printf("%s iPhone打电话给某人", phone)
+}
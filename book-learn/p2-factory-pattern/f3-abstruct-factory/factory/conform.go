@@ -0,0 +1,101 @@
+package factory
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/**
+品牌一致性检查
+每个注册的品牌都要能制造所有种类的产品，新增产品种类时在 productKinds 里加一项
+*/
+
+// productKinds 抽象工厂能制造的产品种类
+var productKinds = []struct {
+	name   string
+	create func(f AbstractFactory) (any, error)
+}{
+	{"watch", func(f AbstractFactory) (any, error) { return f.CreateWatch("") }},
+	{"phone", func(f AbstractFactory) (any, error) { return f.CreatePhone("") }},
+}
+
+// ConformanceError 没有通过一致性检查的品牌和原因
+type ConformanceError struct {
+	Failures []string
+}
+
+func (e *ConformanceError) Error() string {
+	return "factory: conformance failed: " + strings.Join(e.Failures, "; ")
+}
+
+// CheckBrand 检查品牌工厂能否制造每一种产品，返回失败的原因
+func CheckBrand(brand string) error {
+	f, err := NewFactory(brand)
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		return &ConformanceError{Failures: []string{brand + ": nil factory"}}
+	}
+	var failures []string
+	for _, kind := range productKinds {
+		if reason := tryCreate(f, kind.create); reason != "" {
+			failures = append(failures, fmt.Sprintf("%s %s: %s", brand, kind.name, reason))
+		}
+	}
+	if len(failures) > 0 {
+		return &ConformanceError{Failures: failures}
+	}
+	return nil
+}
+
+// Conform 检查所有注册的品牌，可以在测试或启动时调用
+func Conform() error {
+	var failures []string
+	for _, brand := range Brands() {
+		err := CheckBrand(brand)
+		if err == nil {
+			continue
+		}
+		if ce, ok := err.(*ConformanceError); ok {
+			failures = append(failures, ce.Failures...)
+		} else {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return &ConformanceError{Failures: failures}
+	}
+	return nil
+}
+
+// tryCreate 制造一件默认型号的产品，返回出错、为 nil 或者 panic 时的原因
+func tryCreate(f AbstractFactory, create func(f AbstractFactory) (any, error)) (reason string) {
+	defer func() {
+		if p := recover(); p != nil {
+			reason = fmt.Sprintf("panic: %v", p)
+		}
+	}()
+	product, err := create(f)
+	if err != nil {
+		return err.Error()
+	}
+	if isNil(product) {
+		return "nil product"
+	}
+	return ""
+}
+
+// isNil 产品为 nil，或者是 nil 指针
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
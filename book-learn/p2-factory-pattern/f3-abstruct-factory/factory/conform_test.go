@@ -0,0 +1,30 @@
+package factory_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory"
+	_ "design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory/apple"
+	_ "design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory/huawei"
+	_ "design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory/mi"
+)
+
+// TestConform 新增品牌或产品种类时，所有品牌都要能制造每一种产品
+func TestConform(t *testing.T) {
+	brands := factory.Brands()
+	sort.Strings(brands)
+	if want := []string{"apple", "huawei", "mi"}; !reflect.DeepEqual(brands, want) {
+		t.Fatalf("brands = %v, want %v", brands, want)
+	}
+	if err := factory.Conform(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckBrandUnknown(t *testing.T) {
+	if err := factory.CheckBrand("nokia"); err == nil {
+		t.Fatal("unknown brand passed the conformance check")
+	}
+}
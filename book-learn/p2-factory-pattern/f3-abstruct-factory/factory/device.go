@@ -0,0 +1,140 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+/**
+设备的状态
+每台设备有序列号、型号、固件版本和已配对的设备，
+生态规则：手表只能和同一品牌的手机配对，一块手表同时只能配对一台手机
+*/
+
+var (
+	ErrIncompatible  = errors.New("factory: incompatible brands")
+	ErrAlreadyPaired = errors.New("factory: already paired")
+	ErrNotPaired     = errors.New("factory: not paired")
+)
+
+// Device 手机和手表共有的状态，品牌的产品通过嵌入 *BaseDevice 实现
+type Device interface {
+	Serial() string
+	Brand() string
+	Model() string
+	Firmware() string
+	Paired() []Device
+	base() *BaseDevice
+}
+
+// BaseDevice 设备的公共状态，由 Config.NewDevice 创建
+type BaseDevice struct {
+	serial string
+	brand  string
+	model  string
+	logger *log.Logger
+
+	mu       sync.Mutex
+	firmware string
+	paired   map[string]Device // 按序列号
+}
+
+var serialSeq uint64
+
+// nextSerial 品牌前缀加进程内递增的编号
+func nextSerial(brand string) string {
+	return fmt.Sprintf("%s-%08d", strings.ToUpper(brand), atomic.AddUint64(&serialSeq, 1))
+}
+
+func (d *BaseDevice) Serial() string { return d.serial }
+func (d *BaseDevice) Brand() string  { return d.brand }
+func (d *BaseDevice) Model() string  { return d.model }
+
+// Logger 制造设备的工厂的日志
+func (d *BaseDevice) Logger() *log.Logger { return d.logger }
+
+// Firmware 当前固件版本
+func (d *BaseDevice) Firmware() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.firmware
+}
+
+// UpdateFirmware 升级固件
+func (d *BaseDevice) UpdateFirmware(version string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.firmware = version
+}
+
+// Paired 已配对的设备，按序列号排序
+func (d *BaseDevice) Paired() []Device {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	list := make([]Device, 0, len(d.paired))
+	for _, p := range d.paired {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Serial() < list[j].Serial()
+	})
+	return list
+}
+
+func (d *BaseDevice) base() *BaseDevice { return d }
+
+func (d *BaseDevice) String() string {
+	return fmt.Sprintf("%s %s(%s)", d.model, d.serial, d.Firmware())
+}
+
+// Pair 配对手表和手机，品牌不同时返回 ErrIncompatible，
+// 手表已经配对了其他手机时返回 ErrAlreadyPaired
+func Pair(watch IWatch, phone ICallPhone) error {
+	if watch.Brand() != phone.Brand() {
+		return fmt.Errorf("%w: %s watch with %s phone", ErrIncompatible, watch.Brand(), phone.Brand())
+	}
+	w, p := watch.base(), phone.base()
+
+	// 总是先锁手表再锁手机，不会死锁
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for serial := range w.paired { // 手表最多配对一台手机
+		if serial == p.serial {
+			return nil
+		}
+		return fmt.Errorf("%w: %s with %s", ErrAlreadyPaired, w.serial, serial)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	link(w, phone)
+	link(p, watch)
+	return nil
+}
+
+// Unpair 解除配对
+func Unpair(watch IWatch, phone ICallPhone) error {
+	w, p := watch.base(), phone.base()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.paired[p.serial]; !ok {
+		return fmt.Errorf("%w: %s with %s", ErrNotPaired, w.serial, p.serial)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(w.paired, p.serial)
+	delete(p.paired, w.serial)
+	return nil
+}
+
+// link 记录配对，调用方需要持有 d.mu
+func link(d *BaseDevice, other Device) {
+	if d.paired == nil {
+		d.paired = make(map[string]Device)
+	}
+	d.paired[other.Serial()] = other
+}
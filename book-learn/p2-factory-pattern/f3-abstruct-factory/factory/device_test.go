@@ -0,0 +1,128 @@
+package factory_test
+
+import (
+	"errors"
+	"testing"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory"
+)
+
+// devices 每个品牌制造两块手表和两台手机
+type devices struct {
+	watches []factory.IWatch
+	phones  []factory.ICallPhone
+}
+
+func makeDevices(t *testing.T, brands ...string) map[string]*devices {
+	t.Helper()
+	m := make(map[string]*devices)
+	for _, brand := range brands {
+		f, err := factory.NewFactory(brand)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := &devices{}
+		for i := 0; i < 2; i++ {
+			w, err := f.CreateWatch("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := f.CreatePhone("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.watches = append(d.watches, w)
+			d.phones = append(d.phones, p)
+		}
+		m[brand] = d
+	}
+	return m
+}
+
+type pairing struct {
+	watchBrand string
+	watch      int
+	phoneBrand string
+	phone      int
+}
+
+func TestPair(t *testing.T) {
+	tests := []struct {
+		name    string
+		before  []pairing // 先配对好的设备
+		pair    pairing
+		wantErr error
+	}{
+		{"same brand", nil, pairing{"apple", 0, "apple", 0}, nil},
+		{"pair twice", []pairing{{"mi", 0, "mi", 0}}, pairing{"mi", 0, "mi", 0}, nil},
+		{"phone with two watches", []pairing{{"huawei", 0, "huawei", 0}}, pairing{"huawei", 1, "huawei", 0}, nil},
+		{"watch already paired", []pairing{{"apple", 0, "apple", 0}}, pairing{"apple", 0, "apple", 1}, factory.ErrAlreadyPaired},
+		{"apple watch with mi phone", nil, pairing{"apple", 0, "mi", 0}, factory.ErrIncompatible},
+		{"mi watch with huawei phone", nil, pairing{"mi", 0, "huawei", 0}, factory.ErrIncompatible},
+		{"cross brand after pairing", []pairing{{"huawei", 0, "huawei", 0}}, pairing{"huawei", 0, "apple", 0}, factory.ErrIncompatible},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := makeDevices(t, "apple", "huawei", "mi")
+			get := func(p pairing) (factory.IWatch, factory.ICallPhone) {
+				return ds[p.watchBrand].watches[p.watch], ds[p.phoneBrand].phones[p.phone]
+			}
+			for _, p := range tt.before {
+				if err := factory.Pair(get(p)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			watch, phone := get(tt.pair)
+			pairedBefore := len(watch.Paired())
+
+			err := factory.Pair(watch, phone)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Pair err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				// 拒绝的配对不改变任何一方的状态
+				if len(watch.Paired()) != pairedBefore || contains(phone.Paired(), watch) {
+					t.Fatalf("rejected pair changed state: watch %v, phone %v", watch.Paired(), phone.Paired())
+				}
+				return
+			}
+			if ws := watch.Paired(); len(ws) != 1 || ws[0] != phone {
+				t.Fatalf("watch paired = %v, want [%v]", ws, phone)
+			}
+			if !contains(phone.Paired(), watch) {
+				t.Fatalf("phone paired = %v, missing %v", phone.Paired(), watch)
+			}
+		})
+	}
+}
+
+func TestUnpair(t *testing.T) {
+	ds := makeDevices(t, "apple")["apple"]
+	watch, phone, other := ds.watches[0], ds.phones[0], ds.phones[1]
+
+	if err := factory.Unpair(watch, phone); !errors.Is(err, factory.ErrNotPaired) {
+		t.Fatalf("err = %v, want ErrNotPaired", err)
+	}
+	if err := factory.Pair(watch, phone); err != nil {
+		t.Fatal(err)
+	}
+	if err := factory.Unpair(watch, phone); err != nil {
+		t.Fatal(err)
+	}
+	if len(watch.Paired()) != 0 || len(phone.Paired()) != 0 {
+		t.Fatalf("still paired: %v %v", watch.Paired(), phone.Paired())
+	}
+	// 解除后可以配对其他手机
+	if err := factory.Pair(watch, other); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func contains(list []factory.Device, d factory.Device) bool {
+	for _, x := range list {
+		if x == d {
+			return true
+		}
+	}
+	return false
+}
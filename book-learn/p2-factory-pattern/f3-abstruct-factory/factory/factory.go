@@ -0,0 +1,146 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/registry"
+)
+
+/**
+抽象工厂-品牌注册表
+各个品牌在自己的包里注册工厂，使用方按配置或命令行参数里的名字选择品牌，
+新增品牌不需要修改已有的代码
+*/
+
+// AbstractFactory 抽象的手机制造商，可以制造手机和手表。
+// model 为空时制造默认型号，型号不存在时返回 ErrUnknownModel
+type AbstractFactory interface {
+	CreateWatch(model string) (IWatch, error)
+	CreatePhone(model string) (ICallPhone, error)
+}
+
+// IWatch 手表可以看时间
+type IWatch interface {
+	Device
+	WatchTime()
+}
+
+// ICallPhone 手机可以打给某个人
+type ICallPhone interface {
+	Device
+	CallSomebody()
+}
+
+// Config 品牌工厂共用的参数
+type Config struct {
+	Logger    *log.Logger
+	Inventory *Inventory // 为 nil 时不登记
+}
+
+// Option 设置 Config
+type Option func(cfg *Config)
+
+// WithLogger 替换日志，默认不输出
+func WithLogger(logger *log.Logger) Option {
+	return func(cfg *Config) {
+		cfg.Logger = logger
+	}
+}
+
+// WithInventory 制造出来的设备登记到 inv
+func WithInventory(inv *Inventory) Option {
+	return func(cfg *Config) {
+		cfg.Inventory = inv
+	}
+}
+
+// NewDevice 创建设备的公共状态，序列号自动生成
+func (cfg *Config) NewDevice(brand, model, firmware string) *BaseDevice {
+	return &BaseDevice{
+		serial:   nextSerial(brand),
+		brand:    brand,
+		model:    model,
+		firmware: firmware,
+		logger:   cfg.Logger,
+	}
+}
+
+// Manufacture 记录日志并登记到库存，品牌工厂制造出设备后调用
+func (cfg *Config) Manufacture(d Device) error {
+	cfg.Logger.Printf("制造%s %s", d.Model(), d.Serial())
+	if cfg.Inventory == nil {
+		return nil
+	}
+	return cfg.Inventory.Record(d)
+}
+
+// Constructor 创建品牌工厂
+type Constructor func(cfg *Config) AbstractFactory
+
+var (
+	ErrUnknownBrand   = errors.New("factory: unknown brand")
+	ErrDuplicateBrand = errors.New("factory: duplicate brand")
+	ErrUnknownModel   = errors.New("factory: unknown model")
+)
+
+var brands = registry.NewRegistry[string, AbstractFactory]("factory",
+	registry.WithErrors(ErrUnknownBrand, ErrDuplicateBrand))
+
+// Register 注册品牌工厂，同一个品牌只能注册一次
+func Register(brand string, ctor Constructor) error {
+	if ctor == nil {
+		return fmt.Errorf("factory: nil constructor for %q", brand)
+	}
+	return brands.Register(brand, func(args ...any) (AbstractFactory, error) {
+		// 只有 NewFactory 会调用，参数总是一个 *Config
+		if len(args) == 1 {
+			if cfg, ok := args[0].(*Config); ok {
+				return ctor(cfg), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: want *Config", registry.ErrArgs)
+	})
+}
+
+// MustRegister 注册失败时 panic，一般在品牌所在包的 init 中调用
+func MustRegister(brand string, ctor Constructor) {
+	if err := Register(brand, ctor); err != nil {
+		panic(err)
+	}
+}
+
+// NewFactory 按品牌名创建工厂，品牌没有注册时返回 ErrUnknownBrand
+func NewFactory(brand string, opts ...Option) (AbstractFactory, error) {
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = log.New(io.Discard, "", 0)
+	}
+	return brands.New(brand, cfg)
+}
+
+// PickModel 从品牌的型号列表里选出 model，model 为空时取第一个
+func PickModel(brand string, models []string, model string) (string, error) {
+	if model == "" && len(models) > 0 {
+		return models[0], nil
+	}
+	for _, m := range models {
+		if m == model {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s %q", ErrUnknownModel, brand, model)
+}
+
+// Brands 已经注册的品牌，按名字排序
+func Brands() []string {
+	list := brands.Keys()
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,22 @@
+package factory_test
+
+import (
+	"errors"
+	"testing"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory"
+)
+
+func TestRegister(t *testing.T) {
+	// 不注册新品牌，TestConform 会检查品牌列表
+	apple := func(cfg *factory.Config) factory.AbstractFactory { return nil }
+	if err := factory.Register("apple", apple); !errors.Is(err, factory.ErrDuplicateBrand) {
+		t.Fatalf("err = %v, want ErrDuplicateBrand", err)
+	}
+	if err := factory.Register("nokia", nil); err == nil {
+		t.Fatalf("nil constructor: err = %v", err)
+	}
+	if _, err := factory.NewFactory("nokia"); !errors.Is(err, factory.ErrUnknownBrand) {
+		t.Fatalf("err = %v, want ErrUnknownBrand", err)
+	}
+}
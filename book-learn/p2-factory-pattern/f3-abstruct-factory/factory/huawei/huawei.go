@@ -0,0 +1,65 @@
+package huawei
+
+import "design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory"
+
+const brand = "huawei"
+
+var (
+	watchModels = []string{"HUAWEI WATCH 4", "HUAWEI WATCH GT 4"}
+	phoneModels = []string{"Mate 60", "P60"}
+)
+
+const (
+	watchFirmware = "HarmonyOS 4.0"
+	phoneFirmware = "HarmonyOS 4.0"
+)
+
+func init() {
+	factory.MustRegister(brand, func(cfg *factory.Config) factory.AbstractFactory {
+		return &HuaweiFactory{cfg: cfg}
+	})
+}
+
+// HuaweiFactory 华为手机制造商可以制造手机和手表
+type HuaweiFactory struct {
+	cfg *factory.Config
+}
+
+func (huawei *HuaweiFactory) CreateWatch(model string) (factory.IWatch, error) {
+	model, err := factory.PickModel(brand, watchModels, model)
+	if err != nil {
+		return nil, err
+	}
+	watch := &HuaweiWatch{BaseDevice: huawei.cfg.NewDevice(brand, model, watchFirmware)}
+	if err := huawei.cfg.Manufacture(watch); err != nil {
+		return nil, err
+	}
+	return watch, nil
+}
+func (huawei *HuaweiFactory) CreatePhone(model string) (factory.ICallPhone, error) {
+	model, err := factory.PickModel(brand, phoneModels, model)
+	if err != nil {
+		return nil, err
+	}
+	phone := &HuaweiPhone{BaseDevice: huawei.cfg.NewDevice(brand, model, phoneFirmware)}
+	if err := huawei.cfg.Manufacture(phone); err != nil {
+		return nil, err
+	}
+	return phone, nil
+}
+
+type HuaweiWatch struct {
+	*factory.BaseDevice
+}
+
+func (watch *HuaweiWatch) WatchTime() {
+	watch.Logger().Printf("%s 华为手表看时间", watch)
+}
+
+type HuaweiPhone struct {
+	*factory.BaseDevice
+}
+
+func (phone *HuaweiPhone) CallSomebody() {
+	phone.Logger().Printf("%s 华为手机打电话", phone)
+}
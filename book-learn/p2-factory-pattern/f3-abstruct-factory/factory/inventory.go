@@ -0,0 +1,67 @@
+package factory
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Inventory 库存，记录制造出来的设备，可以按品牌和型号查询
+type Inventory struct {
+	mu       sync.RWMutex
+	units    []Device
+	bySerial map[string]Device
+}
+
+// NewInventory 创建空的库存
+func NewInventory() *Inventory {
+	return &Inventory{bySerial: make(map[string]Device)}
+}
+
+// Record 登记设备，序列号重复时返回错误
+func (inv *Inventory) Record(d Device) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if _, ok := inv.bySerial[d.Serial()]; ok {
+		return fmt.Errorf("factory: duplicate serial %s", d.Serial())
+	}
+	inv.bySerial[d.Serial()] = d
+	inv.units = append(inv.units, d)
+	return nil
+}
+
+// Get 按序列号查找设备
+func (inv *Inventory) Get(serial string) (Device, bool) {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	d, ok := inv.bySerial[serial]
+	return d, ok
+}
+
+// Query 按品牌和型号查询，参数为空表示不限，按登记顺序返回
+func (inv *Inventory) Query(brand, model string) []Device {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	var list []Device
+	for _, d := range inv.units {
+		if brand != "" && d.Brand() != brand {
+			continue
+		}
+		if model != "" && d.Model() != model {
+			continue
+		}
+		list = append(list, d)
+	}
+	return list
+}
+
+// Count 按品牌和型号统计数量，参数为空表示不限
+func (inv *Inventory) Count(brand, model string) int {
+	return len(inv.Query(brand, model))
+}
+
+// Len 库存中设备的总数
+func (inv *Inventory) Len() int {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	return len(inv.units)
+}
@@ -0,0 +1,56 @@
+package factory_test
+
+import (
+	"testing"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory"
+)
+
+func TestInventory(t *testing.T) {
+	inv := factory.NewInventory()
+	for _, brand := range []string{"apple", "mi"} {
+		f, err := factory.NewFactory(brand, factory.WithInventory(inv))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.CreateWatch(""); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.CreatePhone(""); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.CreatePhone("no such model"); err == nil {
+			t.Fatal("unknown model accepted")
+		}
+	}
+
+	tests := []struct {
+		brand, model string
+		want         int
+	}{
+		{"", "", 4},
+		{"apple", "", 2},
+		{"mi", "小米14", 1},
+		{"", "iPhone 15", 1},
+		{"huawei", "", 0},
+	}
+	for _, tt := range tests {
+		if got := inv.Count(tt.brand, tt.model); got != tt.want {
+			t.Errorf("Count(%q, %q) = %d, want %d", tt.brand, tt.model, got, tt.want)
+		}
+	}
+
+	all := inv.Query("", "")
+	if all[0].Brand() != "apple" || all[3].Brand() != "mi" {
+		t.Fatalf("Query not in record order: %v", all)
+	}
+	if d, ok := inv.Get(all[1].Serial()); !ok || d != all[1] {
+		t.Fatalf("Get(%s) = %v, %v", all[1].Serial(), d, ok)
+	}
+	if err := inv.Record(all[0]); err == nil {
+		t.Fatal("duplicate serial accepted")
+	}
+	if inv.Len() != 4 {
+		t.Fatalf("Len = %d after duplicate", inv.Len())
+	}
+}
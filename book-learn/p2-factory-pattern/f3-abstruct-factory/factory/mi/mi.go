@@ -0,0 +1,65 @@
+package mi
+
+import "design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory"
+
+const brand = "mi"
+
+var (
+	watchModels = []string{"小米手环8", "小米手表S3"}
+	phoneModels = []string{"小米14", "小米14 Pro"}
+)
+
+const (
+	watchFirmware = "HyperOS 1.0"
+	phoneFirmware = "HyperOS 1.0"
+)
+
+func init() {
+	factory.MustRegister(brand, func(cfg *factory.Config) factory.AbstractFactory {
+		return &MIFactory{cfg: cfg}
+	})
+}
+
+// MIFactory 小米手机制造商可以制造手机和手表
+type MIFactory struct {
+	cfg *factory.Config
+}
+
+func (mi *MIFactory) CreateWatch(model string) (factory.IWatch, error) {
+	model, err := factory.PickModel(brand, watchModels, model)
+	if err != nil {
+		return nil, err
+	}
+	watch := &MIWatch{BaseDevice: mi.cfg.NewDevice(brand, model, watchFirmware)}
+	if err := mi.cfg.Manufacture(watch); err != nil {
+		return nil, err
+	}
+	return watch, nil
+}
+func (mi *MIFactory) CreatePhone(model string) (factory.ICallPhone, error) {
+	model, err := factory.PickModel(brand, phoneModels, model)
+	if err != nil {
+		return nil, err
+	}
+	phone := &MIPhone{BaseDevice: mi.cfg.NewDevice(brand, model, phoneFirmware)}
+	if err := mi.cfg.Manufacture(phone); err != nil {
+		return nil, err
+	}
+	return phone, nil
+}
+
+type MIWatch struct {
+	*factory.BaseDevice
+}
+
+func (miWatch *MIWatch) WatchTime() {
+	miWatch.Logger().Printf("%s 小米手环看时间", miWatch)
+}
+
+type MIPhone struct {
+	*factory.BaseDevice
+}
+
+func (miPhone *MIPhone) CallSomebody() {
+	miPhone.Logger().Printf("%s 小米手机打电话", miPhone)
+}
@@ -1,92 +1,81 @@
 package main
 
-import "fmt"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory"
+	_ "design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory/apple"
+	_ "design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory/huawei"
+	_ "design-pattern-go/book-learn/p2-factory-pattern/f3-abstruct-factory/factory/mi"
+)
 
 /**
 抽象方法
+品牌由命令行参数 -brand 或环境变量 BRAND 选择，多个品牌用逗号分隔，默认所有品牌
 */
 
-//AbstractFactory 抽象的手机制造商，可以制造手机和手表
-type AbstractFactory interface {
-	CreateWatch() IWatch
-	CreatePhone() ICallPhone
-}
-
-// IWatch 手表可以看时间
-type IWatch interface {
-	WatchTime()
-}
-
-// ICallPhone 手机可以打给某个人
-type ICallPhone interface {
-	CallSomebody()
-}
-
-//MIFactory 小米手机制造商可以制造手机和手表
-type MIFactory struct {
-}
-
-func (mi *MIFactory) CreateWatch() IWatch {
-	fmt.Println("制造小米手环")
-	return &MIWatch{}
-}
-func (mi *MIFactory) CreatePhone() ICallPhone {
-	fmt.Println("制造小米手机")
-	return &MIPhone{}
-}
-
-//AppleFactory 苹果手机制造商可以制造手机和手表
-type AppleFactory struct {
-}
-
-func (apple *AppleFactory) CreateWatch() IWatch {
-	fmt.Println("制造Apple Watch")
-	return &AppleWatch{}
-}
-func (apple *AppleFactory) CreatePhone() ICallPhone {
-	fmt.Println("制造iPhone")
-	return &IPhone{}
-}
-
-type MIWatch struct{}
-
-func (miWatch *MIWatch) WatchTime() {
-	fmt.Println("小米手环看时间")
-}
-
-type MIPhone struct{}
-
-func (miPhone *MIPhone) CallSomebody() {
-	fmt.Println("小米手机打电话")
-}
-
-type AppleWatch struct{}
-
-func (watch *AppleWatch) WatchTime() {
-	fmt.Println("Apple Watch看时间")
-}
-
-type IPhone struct{}
-
-func (phone *IPhone) CallSomebody() {
-	fmt.Println("iPhone打电话给某人")
-}
-
 func main() {
-	var factory AbstractFactory
-	var watch IWatch
-	var phone ICallPhone
-
-	factory = &MIFactory{}
-	watch = factory.CreateWatch()
-	watch.WatchTime()
-	phone = factory.CreatePhone()
-	phone.CallSomebody()
+	brand := flag.String("brand", os.Getenv("BRAND"), "品牌，多个用逗号分隔，可选 "+strings.Join(factory.Brands(), ","))
+	flag.Parse()
+
+	logger := log.New(os.Stdout, "", 0)
+	inventory := factory.NewInventory()
+
+	brands := factory.Brands()
+	if *brand != "" {
+		brands = strings.Split(*brand, ",")
+	}
+	var phones []factory.ICallPhone
+	for i, name := range brands {
+		if i > 0 {
+			fmt.Println("------------")
+		}
+		f, err := factory.NewFactory(strings.TrimSpace(name), factory.WithLogger(logger), factory.WithInventory(inventory))
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		watch, err := f.CreateWatch("")
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		watch.WatchTime()
+		phone, err := f.CreatePhone("")
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		phone.CallSomebody()
+		if err := factory.Pair(watch, phone); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Printf("%s 已配对 %v\n", watch.Serial(), watch.Paired())
+		phones = append(phones, phone)
+	}
+
+	// 手表只能和同一品牌的手机配对
+	if len(phones) > 1 {
+		f, _ := factory.NewFactory(phones[0].Brand(), factory.WithLogger(logger), factory.WithInventory(inventory))
+		if watch, err := f.CreateWatch(""); err == nil {
+			err = factory.Pair(watch, phones[1])
+			fmt.Println(err, errors.Is(err, factory.ErrIncompatible))
+		}
+		if _, err := f.CreatePhone("Nokia 3310"); err != nil {
+			fmt.Println(err)
+		}
+	}
 
 	fmt.Println("------------")
-	factory = &AppleFactory{}
-	appleWatch := factory.CreateWatch()
-	appleWatch.WatchTime()
-	iPhone := factory.CreatePhone()
-	iPhone.CallSomebody()
+	for _, name := range factory.Brands() {
+		fmt.Printf("%s 库存 %d 台\n", name, inventory.Count(name, ""))
+	}
+	for _, d := range inventory.Query("", "") {
+		fmt.Println(d.Brand(), d)
+	}
 }
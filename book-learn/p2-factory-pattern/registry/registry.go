@@ -0,0 +1,229 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+/**
+工厂注册表
+按名字创建产品的工厂都要维护一张 名字 -> 构造函数 的表，
+注册表统一处理重复注册、未知名字、别名和废弃提示，并按注册顺序列出
+*/
+
+var (
+	ErrUnknownKey   = errors.New("registry: unknown key")
+	ErrDuplicateKey = errors.New("registry: duplicate key")
+	ErrArgs         = errors.New("registry: unexpected arguments")
+)
+
+// Constructor 创建产品，args 由 New 的调用方传入
+type Constructor[T any] func(args ...any) (T, error)
+
+// NoArgs 把不需要参数的构造函数包装成 Constructor，传入参数时返回 ErrArgs
+func NoArgs[T any](fn func() T) Constructor[T] {
+	return func(args ...any) (T, error) {
+		if len(args) > 0 {
+			var zero T
+			return zero, fmt.Errorf("%w: %d", ErrArgs, len(args))
+		}
+		return fn(), nil
+	}
+}
+
+// Option 设置注册表
+type Option func(cfg *config)
+
+type config struct {
+	unknown   error
+	duplicate error
+	warn      func(msg string)
+}
+
+// WithErrors 替换未知名字和重复注册时返回的错误，为 nil 的保持默认
+func WithErrors(unknown, duplicate error) Option {
+	return func(cfg *config) {
+		if unknown != nil {
+			cfg.unknown = unknown
+		}
+		if duplicate != nil {
+			cfg.duplicate = duplicate
+		}
+	}
+}
+
+// WithWarn 替换输出废弃提示的函数，默认 log.Print
+func WithWarn(warn func(msg string)) Option {
+	return func(cfg *config) {
+		cfg.warn = warn
+	}
+}
+
+// Registry 名字到构造函数的注册表，可以并发使用
+type Registry[K comparable, T any] struct {
+	name string
+	cfg  config
+
+	mu         sync.RWMutex
+	ctors      map[K]Constructor[T]
+	order      []K
+	aliases    map[K]K
+	deprecated map[K]string
+	warned     map[K]bool
+}
+
+// NewRegistry 创建注册表，name 用在废弃提示里
+func NewRegistry[K comparable, T any](name string, opts ...Option) *Registry[K, T] {
+	cfg := config{
+		unknown:   ErrUnknownKey,
+		duplicate: ErrDuplicateKey,
+		warn:      func(msg string) { log.Print(msg) },
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Registry[K, T]{
+		name:       name,
+		cfg:        cfg,
+		ctors:      make(map[K]Constructor[T]),
+		aliases:    make(map[K]K),
+		deprecated: make(map[K]string),
+		warned:     make(map[K]bool),
+	}
+}
+
+// Register 注册构造函数，名字已经注册过或者是别名时返回错误
+func (r *Registry[K, T]) Register(key K, ctor Constructor[T]) error {
+	if ctor == nil {
+		return fmt.Errorf("%s: nil constructor for %s", r.name, format(key))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.taken(key) {
+		return fmt.Errorf("%w: %s", r.cfg.duplicate, format(key))
+	}
+	r.ctors[key] = ctor
+	r.order = append(r.order, key)
+	return nil
+}
+
+// MustRegister 注册失败时 panic，一般在 init 中调用
+func (r *Registry[K, T]) MustRegister(key K, ctor Constructor[T]) {
+	if err := r.Register(key, ctor); err != nil {
+		panic(err)
+	}
+}
+
+// Alias 给已经注册的 target 起一个别名，target 也可以是别名
+func (r *Registry[K, T]) Alias(alias, target K) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.taken(alias) {
+		return fmt.Errorf("%w: %s", r.cfg.duplicate, format(alias))
+	}
+	key, ok := r.resolve(target)
+	if !ok {
+		return fmt.Errorf("%w: %s", r.cfg.unknown, format(target))
+	}
+	r.aliases[alias] = key
+	return nil
+}
+
+// Deprecate 把名字或别名标记为废弃，New 第一次用到它时输出提示；
+// 名字被废弃时，通过它的别名创建也会提示
+func (r *Registry[K, T]) Deprecate(key K, msg string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.taken(key) {
+		return fmt.Errorf("%w: %s", r.cfg.unknown, format(key))
+	}
+	r.deprecated[key] = msg
+	return nil
+}
+
+// New 按名字或别名创建产品，名字不存在时返回未知名字的错误
+func (r *Registry[K, T]) New(key K, args ...any) (T, error) {
+	r.mu.RLock()
+	canonical, ok := r.resolve(key)
+	ctor := r.ctors[canonical]
+	// 别名本身被废弃时提示别名，否则检查它指向的名字
+	deprecatedKey := key
+	msg, deprecated := r.deprecated[key]
+	if !deprecated {
+		deprecatedKey = canonical
+		msg, deprecated = r.deprecated[canonical]
+	}
+	r.mu.RUnlock()
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("%w: %s", r.cfg.unknown, format(key))
+	}
+	if deprecated {
+		r.warnOnce(deprecatedKey, msg)
+	}
+	return ctor(args...)
+}
+
+// Has 名字或别名是否存在
+func (r *Registry[K, T]) Has(key K) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.taken(key)
+}
+
+// Keys 按注册顺序列出名字，不含别名
+func (r *Registry[K, T]) Keys() []K {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]K(nil), r.order...)
+}
+
+// Aliases 别名到名字的对应关系
+func (r *Registry[K, T]) Aliases() map[K]K {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m := make(map[K]K, len(r.aliases))
+	for alias, key := range r.aliases {
+		m[alias] = key
+	}
+	return m
+}
+
+// taken 名字或别名是否已经存在，调用方需要持有 r.mu
+func (r *Registry[K, T]) taken(key K) bool {
+	if _, ok := r.ctors[key]; ok {
+		return true
+	}
+	_, ok := r.aliases[key]
+	return ok
+}
+
+// resolve 把别名换成注册的名字，调用方需要持有 r.mu
+func (r *Registry[K, T]) resolve(key K) (K, bool) {
+	if target, ok := r.aliases[key]; ok {
+		key = target
+	}
+	_, ok := r.ctors[key]
+	return key, ok
+}
+
+func (r *Registry[K, T]) warnOnce(key K, msg string) {
+	r.mu.Lock()
+	if r.warned[key] {
+		r.mu.Unlock()
+		return
+	}
+	r.warned[key] = true
+	r.mu.Unlock()
+	r.cfg.warn(fmt.Sprintf("%s: %s is deprecated: %s", r.name, format(key), msg))
+}
+
+// format 字符串名字加引号
+func format(key any) string {
+	if s, ok := key.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(key)
+}
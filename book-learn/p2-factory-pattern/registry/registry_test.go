@@ -0,0 +1,140 @@
+package registry_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/registry"
+)
+
+// newTestRegistry 注册 a、b、c 三个名字，产品就是名字本身，废弃提示记在 warnings 里
+func newTestRegistry(t *testing.T, opts ...registry.Option) (*registry.Registry[string, string], *[]string) {
+	t.Helper()
+	var warnings []string
+	opts = append([]registry.Option{registry.WithWarn(func(msg string) {
+		warnings = append(warnings, msg)
+	})}, opts...)
+	r := registry.NewRegistry[string, string]("test", opts...)
+	for _, key := range []string{"b", "a", "c"} {
+		key := key
+		r.MustRegister(key, registry.NoArgs(func() string { return key }))
+	}
+	return r, &warnings
+}
+
+func TestRegister(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	if err := r.Register("a", registry.NoArgs(func() string { return "" })); !errors.Is(err, registry.ErrDuplicateKey) {
+		t.Fatalf("duplicate Register err = %v, want ErrDuplicateKey", err)
+	}
+	if err := r.Register("d", nil); err == nil {
+		t.Fatal("nil constructor accepted")
+	}
+	if got, err := r.New("a"); err != nil || got != "a" {
+		t.Fatalf("New(a) = %q, %v", got, err)
+	}
+	if _, err := r.New("z"); !errors.Is(err, registry.ErrUnknownKey) {
+		t.Fatalf("New(z) err = %v, want ErrUnknownKey", err)
+	}
+	if _, err := r.New("a", 1); !errors.Is(err, registry.ErrArgs) {
+		t.Fatalf("New(a, 1) err = %v, want ErrArgs", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustRegister of a duplicate did not panic")
+		}
+	}()
+	r.MustRegister("b", registry.NoArgs(func() string { return "" }))
+}
+
+func TestWithErrors(t *testing.T) {
+	errUnknown := errors.New("unknown op")
+	errDup := errors.New("dup op")
+	r, _ := newTestRegistry(t, registry.WithErrors(errUnknown, errDup))
+	if _, err := r.New("z"); !errors.Is(err, errUnknown) {
+		t.Fatalf("err = %v, want %v", err, errUnknown)
+	}
+	if err := r.Alias("a", "b"); !errors.Is(err, errDup) {
+		t.Fatalf("err = %v, want %v", err, errDup)
+	}
+}
+
+func TestAlias(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	if err := r.Alias("x", "a"); err != nil {
+		t.Fatal(err)
+	}
+	// 别名的别名指向最终的名字
+	if err := r.Alias("y", "x"); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"x", "y"} {
+		if got, err := r.New(key); err != nil || got != "a" {
+			t.Fatalf("New(%s) = %q, %v", key, got, err)
+		}
+	}
+	if want := map[string]string{"x": "a", "y": "a"}; !reflect.DeepEqual(r.Aliases(), want) {
+		t.Fatalf("Aliases = %v, want %v", r.Aliases(), want)
+	}
+	if !r.Has("x") || r.Has("z") {
+		t.Fatal("Has does not see aliases")
+	}
+
+	if err := r.Alias("x", "b"); !errors.Is(err, registry.ErrDuplicateKey) {
+		t.Fatalf("duplicate alias err = %v", err)
+	}
+	if err := r.Alias("b", "a"); !errors.Is(err, registry.ErrDuplicateKey) {
+		t.Fatalf("alias shadowing a key err = %v", err)
+	}
+	if err := r.Alias("w", "z"); !errors.Is(err, registry.ErrUnknownKey) {
+		t.Fatalf("alias to unknown err = %v", err)
+	}
+	if err := r.Register("x", registry.NoArgs(func() string { return "" })); !errors.Is(err, registry.ErrDuplicateKey) {
+		t.Fatalf("register over alias err = %v", err)
+	}
+}
+
+func TestDeprecate(t *testing.T) {
+	r, warnings := newTestRegistry(t)
+	_ = r.Alias("old-a", "a")
+	_ = r.Alias("new-b", "b")
+	if err := r.Deprecate("a", "use c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Deprecate("new-b", "use b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Deprecate("z", ""); !errors.Is(err, registry.ErrUnknownKey) {
+		t.Fatalf("Deprecate(z) err = %v", err)
+	}
+
+	for _, key := range []string{"b", "old-a", "a", "old-a", "new-b", "new-b"} {
+		if _, err := r.New(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 通过别名创建被废弃的名字也会提示，每个名字只提示一次；未废弃的别名指向的 b 不提示
+	want := []string{
+		`test: "a" is deprecated: use c`,
+		`test: "new-b" is deprecated: use b`,
+	}
+	if !reflect.DeepEqual(*warnings, want) {
+		t.Fatalf("warnings = %q, want %q", *warnings, want)
+	}
+}
+
+func TestKeysOrder(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	_ = r.Alias("x", "a")
+	if want := []string{"b", "a", "c"}; !reflect.DeepEqual(r.Keys(), want) {
+		t.Fatalf("Keys = %v, want registration order %v", r.Keys(), want)
+	}
+	// 返回的是副本
+	keys := r.Keys()
+	keys[0] = "z"
+	if r.Keys()[0] != "b" {
+		t.Fatal("Keys exposed internal slice")
+	}
+}
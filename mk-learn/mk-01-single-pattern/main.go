@@ -1,45 +1,74 @@
-package main
+package single_pattern
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"design-pattern-go/book-learn/p1-singleton-pattern/singleton"
 )
 
-type DbPool struct {
-	Host string
-	Port int
-}
+const defaultDbAddr = "127.0.0.1:3306"
+
+// dbPoolInitTimeout 初始化 DbPool 的总时限
+const dbPoolInitTimeout = 10 * time.Second
 
 var dbPoolInit *DbPool
-var lock sync.Locker
+var lock sync.Mutex
 
 //goroutine1进来，实例化dbPoolInit到一半，goroutine2进来
 // dbPoolInit读取到 != nil，返回未完全实例化的 dbPoolInit
 var initialed uint32
 
+// newDefaultDbPool 下面几种传统写法共用：连接 defaultDbAddr，并注册到生命周期管理，
+// 由 singleton.Shutdown 统一关闭
+func newDefaultDbPool() *DbPool {
+	pool, err := NewDbPool(defaultDbAddr)
+	if err != nil {
+		panic(err) // defaultDbAddr 是常量，不会出错
+	}
+	if err := singleton.RegisterCloser("mk-01.dbPoolInit", pool.Close); err != nil {
+		panic(err)
+	}
+	return pool
+}
+
 //通过加锁解决并发
 func GetDBPool_1() *DbPool {
+	if fake, ok := lazyDbPool.Fake(); ok {
+		return fake
+	}
 	lock.Lock() // 如果实例存在没有必要加锁
 	defer lock.Unlock()
 
 	if dbPoolInit == nil {
-		dbPoolInit = &DbPool{}
+		dbPoolInit = newDefaultDbPool()
 	}
 	return dbPoolInit
 }
 
 func GetDBPool_2() *DbPool {
+	if fake, ok := lazyDbPool.Fake(); ok {
+		return fake
+	}
 
 	//这边不是完全原子性
 	if dbPoolInit == nil {
 		lock.Lock()
 		defer lock.Unlock()
-		dbPoolInit = &DbPool{}
+		dbPoolInit = newDefaultDbPool()
 	}
 	return dbPoolInit
 }
 
+var getDbPoolStats = singleton.Track("mk-01.GetDbPool")
+
 func GetDbPool() *DbPool {
+	getDbPoolStats.Access()
+	if fake, ok := lazyDbPool.Fake(); ok {
+		return fake
+	}
 
 	if atomic.LoadUint32(&initialed) == 1 {
 		return dbPoolInit
@@ -49,7 +78,9 @@ func GetDbPool() *DbPool {
 	defer lock.Unlock()
 
 	if atomic.LoadUint32(&initialed) == 0 {
-		dbPoolInit = &DbPool{}
+		start := time.Now()
+		dbPoolInit = newDefaultDbPool()
+		getDbPoolStats.RecordInit(time.Since(start), nil)
 		atomic.StoreUint32(&initialed, 1)
 	}
 
@@ -60,8 +91,106 @@ func GetDbPool() *DbPool {
 var once sync.Once
 
 func GetDbPoolByOnce() *DbPool {
+	if fake, ok := lazyDbPool.Fake(); ok {
+		return fake
+	}
 	once.Do(func() {
-		dbPoolInit = &DbPool{}
+		dbPoolInit = newDefaultDbPool()
 	})
 	return dbPoolInit
 }
+
+var lazyDbPoolStats = singleton.Track("mk-01.GetDbPoolByLazy")
+
+// 通过泛型 Lazy 解决，初始化错误可以返回给调用方，Holder 还支持在测试中替换和重置
+var lazyDbPool = singleton.NewHolder(singleton.TrackInit(lazyDbPoolStats, func() (*DbPool, error) {
+	pool, err := NewDbPool(defaultDbAddr)
+	if err != nil {
+		return nil, err
+	}
+	if err := singleton.RegisterCloser(lazyDbPoolCloser, pool.Close); err != nil {
+		return nil, err
+	}
+	return pool, nil
+})).OnClose(func(pool *DbPool) error {
+	singleton.UnregisterCloser(lazyDbPoolCloser)
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	return pool.Close(ctx)
+})
+
+const lazyDbPoolCloser = "mk-01.lazyDbPool"
+
+func GetDbPoolByLazy() (*DbPool, error) {
+	lazyDbPoolStats.Access()
+	return lazyDbPool.Get()
+}
+
+// OverrideDbPool 测试中替换所有 GetDbPool 系列函数返回的实例，测试结束自动恢复；
+// 替换的实例统一保存在 lazyDbPool 里，每种获取方式都先检查它
+func OverrideDbPool(t singleton.TB, fake *DbPool) {
+	t.Helper()
+	lazyDbPool.Override(t, fake)
+}
+
+// ResetDbPool 关闭并丢弃 GetDbPoolByLazy 已创建的实例，借出的连接归还后才会关闭
+func ResetDbPool() error {
+	return lazyDbPool.Reset()
+}
+
+var retryDbPoolStats = singleton.Track("mk-01.GetDbPoolByRetry")
+
+// 通过 Retry 解决，依赖（比如数据库）还没就绪时，失败不会被永久缓存，退避后下次调用重新初始化
+var retryDbPool = singleton.NewRetry(singleton.TrackInit(retryDbPoolStats, func() (*DbPool, error) {
+	pool, err := NewDbPool(defaultDbAddr)
+	if err != nil {
+		return nil, err
+	}
+	// 先借一个连接确认数据库可达，不可达或连接卡住超时时本次初始化失败
+	ctx, cancel := context.WithTimeout(context.Background(), dbPoolInitTimeout)
+	defer cancel()
+	conn, err := pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pool.Put(conn)
+	if err := singleton.RegisterCloser("mk-01.retryDbPool", pool.Close); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}))
+
+func GetDbPoolByRetry() (*DbPool, error) {
+	retryDbPoolStats.Access()
+	if fake, ok := lazyDbPool.Fake(); ok {
+		return fake, nil
+	}
+	return retryDbPool.Get()
+}
+
+var ctxDbPoolStats = singleton.Track("mk-01.GetDbPoolContext")
+
+// 通过 LazyCtx 解决，初始化卡住时调用方可以按自己的 ctx 超时返回，不影响其他调用方
+var ctxDbPool = singleton.NewLazyCtx(singleton.TrackInitCtx(ctxDbPoolStats, func(ctx context.Context) (*DbPool, error) {
+	pool, err := NewDbPool(defaultDbAddr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pool.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pool.Put(conn)
+	if err := singleton.RegisterCloser("mk-01.ctxDbPool", pool.Close); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}), dbPoolInitTimeout)
+
+func GetDbPoolContext(ctx context.Context) (*DbPool, error) {
+	ctxDbPoolStats.Access()
+	if fake, ok := lazyDbPool.Fake(); ok {
+		return fake, nil
+	}
+	return ctxDbPool.Get(ctx)
+}
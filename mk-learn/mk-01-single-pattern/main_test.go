@@ -0,0 +1,64 @@
+package single_pattern
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOverrideDbPool(t *testing.T) {
+	orig := GetDbPool()
+	if orig.Addr() != defaultDbAddr {
+		t.Fatalf("GetDbPool addr = %s, want %s", orig.Addr(), defaultDbAddr)
+	}
+	fake := &DbPool{Host: "fake"}
+
+	t.Run("override", func(t *testing.T) {
+		OverrideDbPool(t, fake)
+		for name, get := range map[string]func() (*DbPool, error){
+			"GetDBPool_1":      func() (*DbPool, error) { return GetDBPool_1(), nil },
+			"GetDBPool_2":      func() (*DbPool, error) { return GetDBPool_2(), nil },
+			"GetDbPool":        func() (*DbPool, error) { return GetDbPool(), nil },
+			"GetDbPoolByOnce":  func() (*DbPool, error) { return GetDbPoolByOnce(), nil },
+			"GetDbPoolByLazy":  GetDbPoolByLazy,
+			"GetDbPoolByRetry": GetDbPoolByRetry,
+			"GetDbPoolContext": func() (*DbPool, error) { return GetDbPoolContext(context.Background()) },
+		} {
+			pool, err := get()
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if pool != fake {
+				t.Errorf("%s ignored OverrideDbPool", name)
+			}
+		}
+	})
+
+	// 子测试结束后恢复真实实例
+	if GetDbPool() != orig {
+		t.Fatal("GetDbPool not restored")
+	}
+	if pool, err := GetDbPoolByLazy(); err != nil || pool == fake {
+		t.Fatalf("GetDbPoolByLazy not restored: %v", err)
+	}
+}
+
+func TestResetDbPoolCloses(t *testing.T) {
+	old, err := GetDbPoolByLazy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ResetDbPool(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.Get(context.Background()); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("old pool Get err = %v, want ErrPoolClosed", err)
+	}
+	pool, err := GetDbPoolByLazy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool == old {
+		t.Fatal("ResetDbPool did not rebuild the pool")
+	}
+}
@@ -0,0 +1,312 @@
+package single_pattern
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/**
+连接池：对象池 + 单例
+*/
+
+var (
+	ErrPoolClosed = errors.New("db pool: closed")
+)
+
+const defaultMaxIdle = 2
+
+// Dialer 建立连接，可以替换成测试用的桩
+type Dialer interface {
+	Dial(ctx context.Context) (net.Conn, error)
+}
+
+// DialerFunc 函数适配成 Dialer
+type DialerFunc func(ctx context.Context) (net.Conn, error)
+
+func (f DialerFunc) Dial(ctx context.Context) (net.Conn, error) {
+	return f(ctx)
+}
+
+// TCPDialer 通过 TCP 连接 Addr
+type TCPDialer struct {
+	Addr    string
+	Timeout time.Duration
+}
+
+func (d *TCPDialer) Dial(ctx context.Context) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: d.Timeout}
+	return dialer.DialContext(ctx, "tcp", d.Addr)
+}
+
+// PoolOption 设置 DbPool 的参数
+type PoolOption func(p *DbPool)
+
+// WithDialer 替换建立连接的方式，默认通过 TCP 连接 Host:Port
+func WithDialer(dialer Dialer) PoolOption {
+	return func(p *DbPool) {
+		p.dialer = dialer
+	}
+}
+
+// WithMaxOpen 最大连接数（包括使用中和空闲的），<=0 表示不限制
+func WithMaxOpen(n int) PoolOption {
+	return func(p *DbPool) {
+		p.maxOpen = n
+	}
+}
+
+// WithMaxIdle 最大空闲连接数，<=0 使用默认值
+func WithMaxIdle(n int) PoolOption {
+	return func(p *DbPool) {
+		p.maxIdle = n
+	}
+}
+
+// WithIdleTimeout 空闲超过 d 的连接在借出时被关闭，<=0 表示不超时
+func WithIdleTimeout(d time.Duration) PoolOption {
+	return func(p *DbPool) {
+		p.idleTimeout = d
+	}
+}
+
+// WithHealthCheck 借出空闲连接前做健康检查，失败的连接会被关闭
+func WithHealthCheck(check func(net.Conn) error) PoolOption {
+	return func(p *DbPool) {
+		p.healthCheck = check
+	}
+}
+
+type idleConn struct {
+	conn  net.Conn
+	since time.Time
+}
+
+// connRequest 等待连接的请求：conn 不为空表示直接拿到连接，
+// 为空且 err 为空表示拿到一个连接名额，需要自己建立连接
+type connRequest struct {
+	conn net.Conn
+	err  error
+}
+
+// DbPool 数据库连接池，零值可用：默认通过 TCP 连接 Host:Port，不限制连接数
+type DbPool struct {
+	Host string
+	Port int
+
+	dialer      Dialer
+	maxOpen     int
+	maxIdle     int
+	idleTimeout time.Duration
+	healthCheck func(net.Conn) error
+
+	mu       sync.Mutex
+	idle     []idleConn
+	numOpen  int
+	requests []chan connRequest
+	closed   bool
+	drained  chan struct{}
+}
+
+// Addr 连接地址
+func (p *DbPool) Addr() string {
+	return net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+}
+
+func (p *DbPool) getDialer() Dialer {
+	if p.dialer != nil {
+		return p.dialer
+	}
+	return &TCPDialer{Addr: p.Addr()}
+}
+
+func (p *DbPool) getMaxIdle() int {
+	if p.maxIdle <= 0 {
+		return defaultMaxIdle
+	}
+	return p.maxIdle
+}
+
+// Get 借出一个连接，连接数已满时阻塞等待，直到有连接归还或 ctx 取消
+func (p *DbPool) Get(ctx context.Context) (net.Conn, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		// 优先复用空闲连接，后放回的先借出
+		if n := len(p.idle); n > 0 {
+			ic := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			p.mu.Unlock()
+
+			if p.idleTimeout > 0 && time.Since(ic.since) > p.idleTimeout {
+				p.Discard(ic.conn)
+				continue
+			}
+			if p.healthCheck != nil {
+				if err := p.healthCheck(ic.conn); err != nil {
+					p.Discard(ic.conn)
+					continue
+				}
+			}
+			return ic.conn, nil
+		}
+
+		if p.maxOpen <= 0 || p.numOpen < p.maxOpen {
+			p.numOpen++
+			p.mu.Unlock()
+			return p.dial(ctx)
+		}
+
+		// 连接数已满，排队等待
+		req := make(chan connRequest, 1)
+		p.requests = append(p.requests, req)
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			p.mu.Lock()
+			removed := p.removeRequest(req)
+			p.mu.Unlock()
+			if !removed {
+				// 取消的同时已经分配到了连接或名额，归还回去
+				r := <-req
+				if r.conn != nil {
+					p.Put(r.conn)
+				} else if r.err == nil {
+					p.releaseSlot()
+				}
+			}
+			return nil, ctx.Err()
+		case r := <-req:
+			if r.err != nil {
+				return nil, r.err
+			}
+			if r.conn != nil {
+				return r.conn, nil
+			}
+			return p.dial(ctx)
+		}
+	}
+}
+
+// dial 调用方已经占用了一个连接名额，失败时释放名额
+func (p *DbPool) dial(ctx context.Context) (net.Conn, error) {
+	conn, err := p.getDialer().Dial(ctx)
+	if err != nil {
+		p.releaseSlot()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Put 归还连接，优先交给等待的请求，空闲连接已满时直接关闭
+func (p *DbPool) Put(conn net.Conn) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		p.Discard(conn)
+		return
+	}
+	if len(p.requests) > 0 {
+		req := p.requests[0]
+		p.requests = p.requests[1:]
+		p.mu.Unlock()
+		req <- connRequest{conn: conn}
+		return
+	}
+	if len(p.idle) < p.getMaxIdle() {
+		p.idle = append(p.idle, idleConn{conn: conn, since: time.Now()})
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+	p.Discard(conn)
+}
+
+// Discard 关闭一个坏掉的连接并释放名额
+func (p *DbPool) Discard(conn net.Conn) {
+	_ = conn.Close()
+	p.releaseSlot()
+}
+
+// releaseSlot 释放一个连接名额，有等待的请求时把名额转交给它
+func (p *DbPool) releaseSlot() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.closed && len(p.requests) > 0 {
+		req := p.requests[0]
+		p.requests = p.requests[1:]
+		req <- connRequest{}
+		return
+	}
+	p.numOpen--
+	if p.closed && p.numOpen == 0 && p.drained != nil {
+		close(p.drained)
+		p.drained = nil
+	}
+}
+
+// removeRequest 调用方需要持有 p.mu
+func (p *DbPool) removeRequest(req chan connRequest) bool {
+	for i, r := range p.requests {
+		if r == req {
+			p.requests = append(p.requests[:i], p.requests[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Stats 当前打开的连接数和空闲连接数
+func (p *DbPool) Stats() (open, idle int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.numOpen, len(p.idle)
+}
+
+// Close 关闭连接池：关闭空闲连接，唤醒等待的请求，
+// 然后等待借出的连接全部归还，直到 ctx 取消
+func (p *DbPool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPoolClosed
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	requests := p.requests
+	p.requests = nil
+	p.drained = make(chan struct{})
+	drained := p.drained
+	p.mu.Unlock()
+
+	for _, req := range requests {
+		req <- connRequest{err: ErrPoolClosed}
+	}
+	for _, ic := range idle {
+		p.Discard(ic.conn)
+	}
+
+	p.mu.Lock()
+	if p.numOpen == 0 && p.drained != nil {
+		close(p.drained)
+		p.drained = nil
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
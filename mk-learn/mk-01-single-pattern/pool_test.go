@@ -0,0 +1,321 @@
+package single_pattern
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// echoServer 测试用的 TCP 回显服务
+type echoServer struct {
+	ln    net.Listener
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func newEchoServer(t *testing.T) *echoServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &echoServer{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, conn)
+			s.mu.Unlock()
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		_ = ln.Close()
+		s.closeConns()
+	})
+	return s
+}
+
+// closeConns 服务端断开所有连接，模拟数据库重启
+func (s *echoServer) closeConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.conns = nil
+}
+
+// newTestPool 连接 echo 服务，返回连接池和建立连接的次数
+func newTestPool(t *testing.T, s *echoServer, opts ...PoolOption) (*DbPool, *int64) {
+	t.Helper()
+	var dials int64
+	tcp := &TCPDialer{Addr: s.ln.Addr().String(), Timeout: time.Second}
+	dialer := DialerFunc(func(ctx context.Context) (net.Conn, error) {
+		atomic.AddInt64(&dials, 1)
+		return tcp.Dial(ctx)
+	})
+	pool, err := NewDbPool(s.ln.Addr().String(), append([]PoolOption{WithDialer(dialer)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pool, &dials
+}
+
+func ping(conn net.Conn) error {
+	_ = conn.SetDeadline(time.Now().Add(time.Second))
+	defer conn.SetDeadline(time.Time{})
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		return err
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return err
+	}
+	if string(buf) != "ping" {
+		return errors.New("unexpected echo " + string(buf))
+	}
+	return nil
+}
+
+// waitRequests 等到有 n 个请求在排队
+func waitRequests(t *testing.T, p *DbPool, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		p.mu.Lock()
+		got := len(p.requests)
+		p.mu.Unlock()
+		if got == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d requests waiting, want %d", got, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func mustGet(t *testing.T, p *DbPool) net.Conn {
+	t.Helper()
+	conn, err := p.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func TestPoolMaxOpen(t *testing.T) {
+	s := newEchoServer(t)
+	pool, dials := newTestPool(t, s, WithMaxOpen(1))
+	c1 := mustGet(t, pool)
+
+	// 连接数已满，Get 一直阻塞到 ctx 超时
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	waitRequests(t, pool, 0)
+
+	// 归还之后，排队的请求拿到同一个连接
+	got := make(chan net.Conn, 1)
+	go func() {
+		conn, err := pool.Get(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		got <- conn
+	}()
+	waitRequests(t, pool, 1)
+	pool.Put(c1)
+	if c2 := <-got; c2 != c1 {
+		t.Fatal("waiter did not receive the returned conn")
+	}
+	if n := atomic.LoadInt64(dials); n != 1 {
+		t.Fatalf("dialed %d times, want 1", n)
+	}
+
+	// Discard 之后名额转交给排队的请求，由它自己建立连接
+	go func() {
+		conn, err := pool.Get(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		got <- conn
+	}()
+	waitRequests(t, pool, 1)
+	pool.Discard(c1)
+	c3 := <-got
+	if err := ping(c3); err != nil {
+		t.Fatal(err)
+	}
+	if open, _ := pool.Stats(); open != 1 {
+		t.Fatalf("open = %d, want 1", open)
+	}
+}
+
+func TestPoolIdleReuse(t *testing.T) {
+	s := newEchoServer(t)
+	pool, dials := newTestPool(t, s, WithMaxIdle(1))
+
+	c1 := mustGet(t, pool)
+	c2 := mustGet(t, pool)
+	pool.Put(c1)
+	pool.Put(c2) // 空闲连接已满，直接关闭
+	if open, idle := pool.Stats(); open != 1 || idle != 1 {
+		t.Fatalf("open %d idle %d, want 1 1", open, idle)
+	}
+	if err := ping(c2); err == nil {
+		t.Fatal("conn over max idle is still open")
+	}
+
+	c3 := mustGet(t, pool)
+	if c3 != c1 {
+		t.Fatal("idle conn not reused")
+	}
+	if err := ping(c3); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(dials); n != 2 {
+		t.Fatalf("dialed %d times, want 2", n)
+	}
+}
+
+func TestPoolIdleTimeout(t *testing.T) {
+	s := newEchoServer(t)
+	pool, dials := newTestPool(t, s, WithIdleTimeout(20*time.Millisecond))
+
+	c1 := mustGet(t, pool)
+	pool.Put(c1)
+	time.Sleep(40 * time.Millisecond)
+
+	c2 := mustGet(t, pool)
+	if c2 == c1 {
+		t.Fatal("expired idle conn reused")
+	}
+	if err := ping(c1); err == nil {
+		t.Fatal("expired idle conn is still open")
+	}
+	if n := atomic.LoadInt64(dials); n != 2 {
+		t.Fatalf("dialed %d times, want 2", n)
+	}
+	if open, idle := pool.Stats(); open != 1 || idle != 0 {
+		t.Fatalf("open %d idle %d, want 1 0", open, idle)
+	}
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	s := newEchoServer(t)
+	pool, dials := newTestPool(t, s, WithHealthCheck(ping))
+
+	c1 := mustGet(t, pool)
+	pool.Put(c1)
+	if c := mustGet(t, pool); c != c1 {
+		t.Fatal("healthy idle conn not reused")
+	}
+	pool.Put(c1)
+
+	// 服务端断开后，空闲连接检查失败被丢弃，重新建立连接
+	s.closeConns()
+	c2 := mustGet(t, pool)
+	if c2 == c1 {
+		t.Fatal("broken idle conn reused")
+	}
+	if err := ping(c2); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(dials); n != 2 {
+		t.Fatalf("dialed %d times, want 2", n)
+	}
+	if open, _ := pool.Stats(); open != 1 {
+		t.Fatalf("open = %d, want 1", open)
+	}
+}
+
+func TestPoolClose(t *testing.T) {
+	s := newEchoServer(t)
+	pool, _ := newTestPool(t, s, WithMaxOpen(2))
+	c1 := mustGet(t, pool)
+	c2 := mustGet(t, pool)
+
+	// 排队的请求在 Close 时收到 ErrPoolClosed
+	waitErr := make(chan error, 1)
+	go func() {
+		_, err := pool.Get(context.Background())
+		waitErr <- err
+	}()
+	waitRequests(t, pool, 1)
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- pool.Close(context.Background())
+	}()
+	if err := <-waitErr; !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("waiter err = %v, want ErrPoolClosed", err)
+	}
+
+	// 还有借出的连接，Close 等待全部归还
+	pool.Put(c1)
+	select {
+	case err := <-closed:
+		t.Fatalf("Close returned %v before all conns were returned", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	pool.Put(c2)
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+	for _, conn := range []net.Conn{c1, c2} {
+		if err := ping(conn); err == nil {
+			t.Fatal("conn returned after Close is still open")
+		}
+	}
+	if open, idle := pool.Stats(); open != 0 || idle != 0 {
+		t.Fatalf("open %d idle %d, want 0 0", open, idle)
+	}
+
+	if _, err := pool.Get(context.Background()); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("Get after Close: %v, want ErrPoolClosed", err)
+	}
+	if err := pool.Close(context.Background()); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("second Close: %v, want ErrPoolClosed", err)
+	}
+}
+
+func TestPoolCloseTimeout(t *testing.T) {
+	s := newEchoServer(t)
+	pool, _ := newTestPool(t, s)
+	borrowed := mustGet(t, pool)
+	idle := mustGet(t, pool)
+	pool.Put(idle)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	// 空闲连接在 Close 时立即关闭
+	if err := ping(idle); err == nil {
+		t.Fatal("idle conn is still open after Close")
+	}
+	// 超时之后归还的连接仍然会被关闭
+	pool.Put(borrowed)
+	if err := ping(borrowed); err == nil {
+		t.Fatal("conn returned after Close is still open")
+	}
+	if open, _ := pool.Stats(); open != 0 {
+		t.Fatalf("open = %d, want 0", open)
+	}
+}
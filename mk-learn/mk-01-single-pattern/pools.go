@@ -0,0 +1,72 @@
+package single_pattern
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"design-pattern-go/book-learn/p1-singleton-pattern/singleton"
+)
+
+/**
+多例：每个数据库地址一个 DbPool
+*/
+
+const closeTimeout = 5 * time.Second
+
+// NewDbPool 根据 "host:port" 创建 DbPool，此时还不会建立连接
+func NewDbPool(addr string, opts ...PoolOption) (*DbPool, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid addr is %s: %w", addr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return nil, fmt.Errorf("invalid Port is %s", portStr)
+	}
+	pool := &DbPool{
+		Host: host,
+		Port: port,
+	}
+	for _, opt := range opts {
+		opt(pool)
+	}
+	return pool, nil
+}
+
+var pools = singleton.NewMultiton(func(addr string) (*DbPool, error) {
+	pool, err := NewDbPool(addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := singleton.RegisterCloser(poolCloserName(addr), pool.Close); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}).OnClose(func(addr string, pool *DbPool) error {
+	singleton.UnregisterCloser(poolCloserName(addr))
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	return pool.Close(ctx)
+})
+
+func poolCloserName(addr string) string {
+	return "mk-01.pools/" + addr
+}
+
+// GetPool 获取 addr（"host:port"）对应的 DbPool，每个地址只创建一次
+func GetPool(addr string) (*DbPool, error) {
+	return pools.Get(addr)
+}
+
+// ClosePool 移除并关闭 addr 对应的 DbPool
+func ClosePool(addr string) error {
+	return pools.Evict(addr)
+}
+
+// PoolKeys 当前存活的 DbPool 地址
+func PoolKeys() []string {
+	return pools.Keys()
+}
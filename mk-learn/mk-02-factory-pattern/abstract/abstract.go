@@ -0,0 +1,298 @@
+package abstract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/registry"
+)
+
+/**
+抽象工厂
+*/
+
+/*
+ 在小明的学校，每一年开学都会发教材，
+主要包括语文书、数学书、英语书，还有各种练习试卷。
+这一天，小明去领了三本教材，分别是语文书、数学书和英语书，老师忙不过来，指定某个同学去发书，
+同学们都去这个同学这里去领书。这个同学就是工厂。
+*/
+
+var (
+	ErrUnknownTitle   = errors.New("assigner: unknown title")
+	ErrOutOfStock     = errors.New("assigner: out of stock")
+	ErrAlreadyIssued  = errors.New("assigner: already issued")
+	ErrUnknownStudent = errors.New("assigner: unknown student")
+	ErrDuplicateTitle = errors.New("assigner: duplicate title")
+)
+
+type Book interface {
+	Name() string
+}
+
+type Paper interface {
+	Name() string
+}
+
+type chineseBook struct {
+	name string
+}
+
+type chinesePaper struct {
+	name string
+}
+
+func (cb *chineseBook) Name() string {
+	return cb.name
+}
+
+func (cp *chinesePaper) Name() string {
+	return cp.name
+}
+
+type mathBook struct {
+	name string
+}
+
+func (mb *mathBook) Name() string {
+	return mb.name
+}
+
+type mathPaper struct {
+	name string
+}
+
+func (mp *mathPaper) Name() string {
+	return mp.name
+}
+
+type englishBook struct {
+	name string
+}
+
+func (eb *englishBook) Name() string {
+	return eb.name
+}
+
+type englishPaper struct {
+	name string
+}
+
+func (ep *englishPaper) Name() string {
+	return ep.name
+}
+
+// 发书人，书名或试卷名不存在时返回 ErrUnknownTitle，没有库存时返回 ErrOutOfStock
+type Assigner interface {
+	GetBook(name string) (Book, error)
+	GetPaper(name string) (Paper, error)
+}
+
+// SubjectAssigner 负责某一科的发书人
+type SubjectAssigner interface {
+	Assigner
+	Subject() string
+	Titles() []string
+}
+
+// subjectAssigner 某一科的书和试卷，从库存中扣减
+type subjectAssigner struct {
+	subject  string
+	book     string
+	paper    string
+	newBook  func(name string) Book
+	newPaper func(name string) Paper
+	stock    *Stock
+	exam     *Exam
+}
+
+// AssignerOption 设置某一科的发书人
+type AssignerOption func(sa *subjectAssigner)
+
+// WithExam 试卷按 exam 从题库现场组卷，不再占用库存
+func WithExam(exam *Exam) AssignerOption {
+	return func(sa *subjectAssigner) {
+		sa.exam = exam
+	}
+}
+
+func newSubjectAssigner(sa *subjectAssigner, opts []AssignerOption) SubjectAssigner {
+	for _, opt := range opts {
+		opt(sa)
+	}
+	return sa
+}
+
+// NewChineseAssigner 发语文书和语文试卷
+func NewChineseAssigner(stock *Stock, opts ...AssignerOption) SubjectAssigner {
+	return newSubjectAssigner(&subjectAssigner{
+		subject:  "语文",
+		book:     "语文书",
+		paper:    "语文试卷",
+		newBook:  func(name string) Book { return &chineseBook{name: name} },
+		newPaper: func(name string) Paper { return &chinesePaper{name: name} },
+		stock:    stock,
+	}, opts)
+}
+
+// NewMathAssigner 发数学书和数学试卷
+func NewMathAssigner(stock *Stock, opts ...AssignerOption) SubjectAssigner {
+	return newSubjectAssigner(&subjectAssigner{
+		subject:  "数学",
+		book:     "数学书",
+		paper:    "数学试卷",
+		newBook:  func(name string) Book { return &mathBook{name: name} },
+		newPaper: func(name string) Paper { return &mathPaper{name: name} },
+		stock:    stock,
+	}, opts)
+}
+
+// NewEnglishAssigner 发英语书和英语试卷
+func NewEnglishAssigner(stock *Stock, opts ...AssignerOption) SubjectAssigner {
+	return newSubjectAssigner(&subjectAssigner{
+		subject:  "英语",
+		book:     "英语书",
+		paper:    "英语试卷",
+		newBook:  func(name string) Book { return &englishBook{name: name} },
+		newPaper: func(name string) Paper { return &englishPaper{name: name} },
+		stock:    stock,
+	}, opts)
+}
+
+func (sa *subjectAssigner) Subject() string {
+	return sa.subject
+}
+
+func (sa *subjectAssigner) Titles() []string {
+	return []string{sa.book, sa.paper}
+}
+
+func (sa *subjectAssigner) GetBook(name string) (Book, error) {
+	if name != sa.book {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTitle, name)
+	}
+	if err := sa.stock.Take(name); err != nil {
+		return nil, err
+	}
+	return sa.newBook(name), nil
+}
+
+// GetPaper 配置了考试时按基础种子组卷，否则从库存中取出一份
+func (sa *subjectAssigner) GetPaper(name string) (Paper, error) {
+	if name != sa.paper {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTitle, name)
+	}
+	if sa.exam != nil {
+		paper, _, err := sa.exam.Generate(sa.exam.Spec.Seed)
+		if err != nil {
+			return nil, err
+		}
+		return paper, nil
+	}
+	if err := sa.stock.Take(name); err != nil {
+		return nil, err
+	}
+	return sa.newPaper(name), nil
+}
+
+// reserver 支持预留的发书人
+type reserver interface {
+	hasBook(name string) bool
+	available(name string) int
+	reserveBook(ctx context.Context, name string, ttl time.Duration) (*Hold, error)
+	tryReserveBook(name string, ttl time.Duration) (*Hold, error)
+	bookOf(name string) Book
+}
+
+func (sa *subjectAssigner) hasBook(name string) bool {
+	return name == sa.book
+}
+
+func (sa *subjectAssigner) available(name string) int {
+	return sa.stock.Count(name)
+}
+
+func (sa *subjectAssigner) reserveBook(ctx context.Context, name string, ttl time.Duration) (*Hold, error) {
+	if name != sa.book {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTitle, name)
+	}
+	return sa.stock.Reserve(ctx, name, ttl)
+}
+
+func (sa *subjectAssigner) tryReserveBook(name string, ttl time.Duration) (*Hold, error) {
+	if name != sa.book {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTitle, name)
+	}
+	return sa.stock.TryReserve(name, ttl)
+}
+
+// bookOf 预留确认后创建书，库存已经扣过了
+func (sa *subjectAssigner) bookOf(name string) Book {
+	return sa.newBook(name)
+}
+
+// examiner 能给每个学生单独组卷的发书人
+type examiner interface {
+	paperFor(name, studentID string) (Paper, bool, error)
+}
+
+// paperFor 没有配置考试时返回 false
+func (sa *subjectAssigner) paperFor(name, studentID string) (Paper, bool, error) {
+	if sa.exam == nil || name != sa.paper {
+		return nil, false, nil
+	}
+	paper, err := sa.exam.PaperFor(studentID)
+	if err != nil {
+		return nil, true, err
+	}
+	return paper, true, nil
+}
+
+// assigner 按书名把请求转给对应科目的发书人
+type assigner struct {
+	titles *registry.Registry[string, SubjectAssigner]
+}
+
+// NewAssigner 组合各科的发书人，书名重复时返回 ErrDuplicateTitle
+func NewAssigner(subjects ...SubjectAssigner) (Assigner, error) {
+	return newAssigner(subjects)
+}
+
+func newAssigner(subjects []SubjectAssigner) (*assigner, error) {
+	a := &assigner{
+		titles: registry.NewRegistry[string, SubjectAssigner]("assigner",
+			registry.WithErrors(ErrUnknownTitle, ErrDuplicateTitle)),
+	}
+	for _, sa := range subjects {
+		sa := sa
+		for _, title := range sa.Titles() {
+			if err := a.titles.Register(title, registry.NoArgs(func() SubjectAssigner { return sa })); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return a, nil
+}
+
+func (a *assigner) GetBook(name string) (Book, error) {
+	sa, err := a.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return sa.GetBook(name)
+}
+
+func (a *assigner) GetPaper(name string) (Paper, error) {
+	sa, err := a.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return sa.GetPaper(name)
+}
+
+// lookup 书名对应科目的发书人，书名不存在时返回 ErrUnknownTitle
+func (a *assigner) lookup(title string) (SubjectAssigner, error) {
+	return a.titles.New(title)
+}
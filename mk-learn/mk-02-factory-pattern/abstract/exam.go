@@ -0,0 +1,302 @@
+package abstract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+/**
+试卷生成
+每科一个 JSON 题库，按难度比例、知识点覆盖和题目数量组卷，
+每个学生一个随机种子，同一个种子总是组出同一份试卷，答案单独生成，不放在试卷里
+*/
+
+var (
+	ErrInvalidSpec         = errors.New("assigner: invalid paper spec")
+	ErrNotEnoughQuestions  = errors.New("assigner: not enough questions")
+	ErrDuplicateQuestionID = errors.New("assigner: duplicate question id")
+)
+
+// Difficulty 题目难度
+type Difficulty string
+
+const (
+	Easy   Difficulty = "easy"
+	Medium Difficulty = "medium"
+	Hard   Difficulty = "hard"
+)
+
+// Question 题库中的一道题
+type Question struct {
+	ID         string     `json:"id"`
+	Topic      string     `json:"topic"`
+	Difficulty Difficulty `json:"difficulty"`
+	Text       string     `json:"text"`
+	Choices    []string   `json:"choices,omitempty"`
+	Answer     string     `json:"answer"`
+}
+
+// QuestionBank 某一科的题库
+type QuestionBank struct {
+	Subject   string     `json:"subject"`
+	Questions []Question `json:"questions"`
+}
+
+// LoadBank 从 JSON 读取题库，题目编号重复时返回错误
+func LoadBank(r io.Reader) (*QuestionBank, error) {
+	bank := &QuestionBank{}
+	if err := json.NewDecoder(r).Decode(bank); err != nil {
+		return nil, fmt.Errorf("assigner: parse question bank: %w", err)
+	}
+	seen := make(map[string]bool, len(bank.Questions))
+	for _, q := range bank.Questions {
+		if seen[q.ID] {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateQuestionID, q.ID)
+		}
+		seen[q.ID] = true
+	}
+	return bank, nil
+}
+
+// LoadBankFile 从 JSON 文件读取题库
+func LoadBankFile(path string) (*QuestionBank, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadBank(f)
+}
+
+// PaperSpec 组卷要求
+type PaperSpec struct {
+	Title  string             `json:"title"`
+	Count  int                `json:"count"`  // 题目数量
+	Mix    map[Difficulty]int `json:"mix"`    // 各难度的百分比，合计 100
+	Topics []string           `json:"topics"` // 每个知识点至少出一道题
+	Seed   int64              `json:"seed"`   // 基础种子，和学号一起算出每个学生的种子
+}
+
+// counts 按百分比分配各难度的题目数量，余数给小数部分大的难度
+func (spec PaperSpec) counts() (map[Difficulty]int, error) {
+	if spec.Count <= 0 {
+		return nil, fmt.Errorf("%w: count %d", ErrInvalidSpec, spec.Count)
+	}
+	total := 0
+	levels := make([]Difficulty, 0, len(spec.Mix))
+	for d, pct := range spec.Mix {
+		if pct < 0 {
+			return nil, fmt.Errorf("%w: %s %d%%", ErrInvalidSpec, d, pct)
+		}
+		total += pct
+		levels = append(levels, d)
+	}
+	if total != 100 {
+		return nil, fmt.Errorf("%w: mix adds up to %d%%", ErrInvalidSpec, total)
+	}
+	if len(spec.Topics) > spec.Count {
+		return nil, fmt.Errorf("%w: %d topics for %d questions", ErrInvalidSpec, len(spec.Topics), spec.Count)
+	}
+
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+	counts := make(map[Difficulty]int, len(levels))
+	assigned := 0
+	for _, d := range levels {
+		counts[d] = spec.Count * spec.Mix[d] / 100
+		assigned += counts[d]
+	}
+	sort.SliceStable(levels, func(i, j int) bool {
+		return spec.Count*spec.Mix[levels[i]]%100 > spec.Count*spec.Mix[levels[j]]%100
+	})
+	for i := 0; assigned < spec.Count; i++ {
+		counts[levels[i]]++
+		assigned++
+	}
+	return counts, nil
+}
+
+// PaperQuestion 试卷上的题目，不含答案
+type PaperQuestion struct {
+	No      int      `json:"no"`
+	ID      string   `json:"id"`
+	Topic   string   `json:"topic"`
+	Text    string   `json:"text"`
+	Choices []string `json:"choices,omitempty"`
+}
+
+// ExamPaper 组好的试卷，实现 Paper
+type ExamPaper struct {
+	Title     string          `json:"title"`
+	Subject   string          `json:"subject"`
+	Seed      int64           `json:"seed"`
+	Questions []PaperQuestion `json:"questions"`
+}
+
+func (p *ExamPaper) Name() string {
+	return p.Title
+}
+
+// AnswerKey 试卷的答案，只给老师
+type AnswerKey struct {
+	Title   string   `json:"title"`
+	Seed    int64    `json:"seed"`
+	Answers []string `json:"answers"` // 按题号顺序
+}
+
+// Exam 一次考试：题库加组卷要求
+type Exam struct {
+	Bank *QuestionBank
+	Spec PaperSpec
+}
+
+// SeedFor 学生的种子，由基础种子和学号算出
+func (e *Exam) SeedFor(studentID string) int64 {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d/%s", e.Spec.Seed, studentID)
+	return int64(h.Sum64() & (1<<63 - 1))
+}
+
+// PaperFor 给学生组卷
+func (e *Exam) PaperFor(studentID string) (*ExamPaper, error) {
+	paper, _, err := e.Generate(e.SeedFor(studentID))
+	return paper, err
+}
+
+// AnswerKey 按试卷上的种子重新组卷，得到对应的答案
+func (e *Exam) AnswerKey(seed int64) (*AnswerKey, error) {
+	_, key, err := e.Generate(seed)
+	return key, err
+}
+
+// Generate 用 seed 组卷。先给每个知识点各抽一道题，再按难度补足数量，
+// 题库不够时返回 ErrNotEnoughQuestions
+func (e *Exam) Generate(seed int64) (*ExamPaper, *AnswerKey, error) {
+	counts, err := e.Spec.counts()
+	if err != nil {
+		return nil, nil, err
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	// 题库的顺序固定后再洗牌，同一个种子的结果才稳定
+	pool := append([]Question(nil), e.Bank.Questions...)
+	sort.Slice(pool, func(i, j int) bool { return pool[i].ID < pool[j].ID })
+	rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+
+	used := make([]bool, len(pool))
+	var picked []Question
+	take := func(match func(q Question) bool) bool {
+		for i, q := range pool {
+			if !used[i] && counts[q.Difficulty] > 0 && match(q) {
+				used[i] = true
+				counts[q.Difficulty]--
+				picked = append(picked, q)
+				return true
+			}
+		}
+		return false
+	}
+
+	topics := uniqueTopics(e.Spec.Topics)
+	rng.Shuffle(len(topics), func(i, j int) { topics[i], topics[j] = topics[j], topics[i] })
+	assigned, err := assignTopics(topics, pool, counts)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, topic := range topics {
+		topic, d := topic, assigned[topic]
+		take(func(q Question) bool { return q.Topic == topic && q.Difficulty == d })
+	}
+	for len(picked) < e.Spec.Count {
+		if !take(func(Question) bool { return true }) {
+			return nil, nil, fmt.Errorf("%w: need %d, got %d for %v", ErrNotEnoughQuestions, e.Spec.Count, len(picked), counts)
+		}
+	}
+	rng.Shuffle(len(picked), func(i, j int) { picked[i], picked[j] = picked[j], picked[i] })
+
+	paper := &ExamPaper{Title: e.Spec.Title, Subject: e.Bank.Subject, Seed: seed}
+	key := &AnswerKey{Title: e.Spec.Title, Seed: seed}
+	for i, q := range picked {
+		paper.Questions = append(paper.Questions, PaperQuestion{
+			No:      i + 1,
+			ID:      q.ID,
+			Topic:   q.Topic,
+			Text:    q.Text,
+			Choices: q.Choices,
+		})
+		key.Answers = append(key.Answers, q.Answer)
+	}
+	return paper, key, nil
+}
+
+func uniqueTopics(topics []string) []string {
+	seen := make(map[string]bool, len(topics))
+	var list []string
+	for _, topic := range topics {
+		if !seen[topic] {
+			seen[topic] = true
+			list = append(list, topic)
+		}
+	}
+	return list
+}
+
+// assignTopics 给每个知识点分配一个难度，各难度分到的知识点不超过 counts。
+// 一道题只属于一个知识点，不同知识点选出的题不会重复，所以只需要匹配知识点和难度，
+// 用增广路径做二分匹配，只要存在满足要求的组卷方式就一定能找到
+func assignTopics(topics []string, pool []Question, counts map[Difficulty]int) (map[string]Difficulty, error) {
+	// 每个知识点可选的难度，按洗牌后题目出现的顺序
+	options := make(map[string][]Difficulty, len(topics))
+	for _, q := range pool {
+		list := options[q.Topic]
+		found := false
+		for _, d := range list {
+			if d == q.Difficulty {
+				found = true
+				break
+			}
+		}
+		if !found {
+			options[q.Topic] = append(list, q.Difficulty)
+		}
+	}
+
+	assigned := make(map[string]Difficulty, len(topics))
+	holders := make(map[Difficulty][]string) // 每个难度分到的知识点
+
+	var augment func(topic string, visited map[Difficulty]bool) bool
+	augment = func(topic string, visited map[Difficulty]bool) bool {
+		for _, d := range options[topic] {
+			if visited[d] || counts[d] == 0 {
+				continue
+			}
+			visited[d] = true
+			if len(holders[d]) < counts[d] {
+				holders[d] = append(holders[d], topic)
+				assigned[topic] = d
+				return true
+			}
+			// 难度已满，看能不能把占着的知识点挪到别的难度
+			for i, other := range holders[d] {
+				if augment(other, visited) {
+					holders[d][i] = topic
+					assigned[topic] = d
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for _, topic := range topics {
+		if !augment(topic, make(map[Difficulty]bool)) {
+			return nil, fmt.Errorf("%w: topic %q", ErrNotEnoughQuestions, topic)
+		}
+	}
+	return assigned, nil
+}
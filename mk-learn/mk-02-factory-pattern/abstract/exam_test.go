@@ -0,0 +1,133 @@
+package abstract
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// tightExam 只有 {q2, q3, q4} 一种组卷方式满足要求
+func tightExam() *Exam {
+	return &Exam{
+		Bank: &QuestionBank{
+			Subject: "语文",
+			Questions: []Question{
+				{ID: "q1", Topic: "A", Difficulty: Hard, Answer: "1"},
+				{ID: "q2", Topic: "A", Difficulty: Easy, Answer: "2"},
+				{ID: "q3", Topic: "B", Difficulty: Hard, Answer: "3"},
+				{ID: "q4", Topic: "C", Difficulty: Easy, Answer: "4"},
+			},
+		},
+		Spec: PaperSpec{
+			Title:  "语文试卷",
+			Count:  3,
+			Mix:    map[Difficulty]int{Easy: 67, Hard: 33},
+			Topics: []string{"A", "B"},
+		},
+	}
+}
+
+func TestGenerateTightBank(t *testing.T) {
+	exam := tightExam()
+	for seed := int64(0); seed < 1000; seed++ {
+		paper, key, err := exam.Generate(seed)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		ids := make(map[string]bool)
+		for _, q := range paper.Questions {
+			ids[q.ID] = true
+		}
+		if len(ids) != 3 || !ids["q2"] || !ids["q3"] || !ids["q4"] {
+			t.Fatalf("seed %d: got %v, want q2 q3 q4", seed, ids)
+		}
+		if len(key.Answers) != len(paper.Questions) {
+			t.Fatalf("seed %d: %d answers for %d questions", seed, len(key.Answers), len(paper.Questions))
+		}
+	}
+}
+
+func TestGenerateCoverage(t *testing.T) {
+	bank := &QuestionBank{Subject: "数学"}
+	topics := []string{"加法", "减法", "乘法", "除法"}
+	levels := []Difficulty{Easy, Medium, Hard}
+	for i := 0; i < 24; i++ {
+		bank.Questions = append(bank.Questions, Question{
+			ID:         string(rune('a' + i)),
+			Topic:      topics[i%len(topics)],
+			Difficulty: levels[i%len(levels)],
+			Answer:     string(rune('A' + i)),
+		})
+	}
+	exam := &Exam{
+		Bank: bank,
+		Spec: PaperSpec{
+			Count:  6,
+			Mix:    map[Difficulty]int{Easy: 50, Medium: 30, Hard: 20},
+			Topics: topics,
+		},
+	}
+	wantMix := map[Difficulty]int{Easy: 3, Medium: 2, Hard: 1}
+	difficulty := make(map[string]Difficulty)
+	for _, q := range bank.Questions {
+		difficulty[q.ID] = q.Difficulty
+	}
+
+	for seed := int64(0); seed < 500; seed++ {
+		paper, _, err := exam.Generate(seed)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		mix := make(map[Difficulty]int)
+		covered := make(map[string]bool)
+		for _, q := range paper.Questions {
+			mix[difficulty[q.ID]]++
+			covered[q.Topic] = true
+		}
+		if !reflect.DeepEqual(mix, wantMix) {
+			t.Fatalf("seed %d: mix %v, want %v", seed, mix, wantMix)
+		}
+		for _, topic := range topics {
+			if !covered[topic] {
+				t.Fatalf("seed %d: topic %q not covered", seed, topic)
+			}
+		}
+	}
+}
+
+func TestGenerateReproducible(t *testing.T) {
+	exam := tightExam()
+	exam.Spec.Seed = 2024
+	a, keyA, err := exam.Generate(exam.SeedFor("001"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := exam.PaperFor("001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("same seed gave different papers:\n%v\n%v", a, b)
+	}
+	keyB, err := exam.AnswerKey(b.Seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keyA, keyB) {
+		t.Fatalf("answer key not reproducible: %v, %v", keyA, keyB)
+	}
+}
+
+func TestGenerateInfeasible(t *testing.T) {
+	exam := tightExam()
+	// 只有一道 hard，A 和 B 都要占一道 hard 才能覆盖时无解
+	exam.Bank.Questions = exam.Bank.Questions[:1]
+	exam.Bank.Questions = append(exam.Bank.Questions,
+		Question{ID: "q3", Topic: "B", Difficulty: Hard},
+		Question{ID: "q4", Topic: "C", Difficulty: Easy},
+		Question{ID: "q5", Topic: "C", Difficulty: Easy},
+	)
+	if _, _, err := exam.Generate(1); !errors.Is(err, ErrNotEnoughQuestions) {
+		t.Fatalf("err = %v, want ErrNotEnoughQuestions", err)
+	}
+}
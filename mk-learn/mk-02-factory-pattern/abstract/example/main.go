@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"design-pattern-go/mk-learn/mk-02-factory-pattern/abstract"
+)
+
+//go:embed banks
+var banks embed.FS
+
+func main() {
+	f, err := banks.Open("banks/chinese.json")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	bank, err := abstract.LoadBank(f)
+	f.Close()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	exam := &abstract.Exam{
+		Bank: bank,
+		Spec: abstract.PaperSpec{
+			Title:  "语文试卷",
+			Count:  5,
+			Mix:    map[abstract.Difficulty]int{abstract.Easy: 40, abstract.Medium: 40, abstract.Hard: 20},
+			Topics: []string{"古诗", "成语", "阅读"},
+			Seed:   2024,
+		},
+	}
+
+	stock := abstract.NewStock()
+	_ = stock.Add("语文书", 2)
+	_ = stock.Add("数学书", 1)
+	_ = stock.Add("英语书", 2)
+
+	roster, err := abstract.NewRoster(
+		abstract.Student{ID: "001", Name: "小明", Class: "一班"},
+		abstract.Student{ID: "002", Name: "小红", Class: "一班"},
+		abstract.Student{ID: "003", Name: "小刚", Class: "二班"},
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	service, err := abstract.NewService(roster,
+		abstract.NewChineseAssigner(stock, abstract.WithExam(exam)),
+		abstract.NewMathAssigner(stock),
+		abstract.NewEnglishAssigner(stock),
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, title := range []string{"语文书", "数学书", "英语书"} {
+		book, err := service.IssueBook("001", title)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println("小明领到了", book.Name())
+	}
+	// 每个学生一份试卷，答案按试卷上的种子重新生成
+	for _, id := range []string{"001", "002"} {
+		paper, err := service.IssuePaper(id, "语文试卷")
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		p := paper.(*abstract.ExamPaper)
+		fmt.Printf("%s 领到了 %s（种子 %d）\n", id, p.Name(), p.Seed)
+		for _, q := range p.Questions {
+			fmt.Printf("  %d. [%s] %s\n", q.No, q.Topic, q.Text)
+			if len(q.Choices) > 0 {
+				fmt.Println("    ", strings.Join(q.Choices, " / "))
+			}
+		}
+		if key, err := exam.AnswerKey(p.Seed); err == nil {
+			fmt.Println("  答案:", key.Answers)
+		}
+	}
+
+	cases := []struct {
+		student, title string
+	}{
+		{"001", "语文书"},
+		{"002", "数学书"},
+		{"002", "物理书"},
+		{"009", "语文书"},
+	}
+	for _, c := range cases {
+		_, err := service.IssueBook(c.student, c.title)
+		switch {
+		case errors.Is(err, abstract.ErrAlreadyIssued):
+			fmt.Println("已经领过:", err)
+		case errors.Is(err, abstract.ErrOutOfStock):
+			fmt.Println("没有库存:", err)
+		case errors.Is(err, abstract.ErrUnknownTitle):
+			fmt.Println("没有这本书:", err)
+		case err != nil:
+			fmt.Println(err)
+		}
+	}
+
+	// 预留：超时没有确认的书会回到库存
+	if r, err := service.ReserveBook(context.Background(), "003", "语文书", 10*time.Millisecond); err == nil {
+		fmt.Println("小刚预留了", r.Title(), "剩余", stock.Count("语文书"))
+		time.Sleep(20 * time.Millisecond)
+		if _, err := r.Confirm(); err != nil {
+			fmt.Println(err, "剩余", stock.Count("语文书"))
+		}
+	}
+
+	// 按花名册批量发书，先试运行核对库存
+	roster2 := `id,name,class,titles
+003,小刚,二班,语文书;英语书
+004,小丽,二班,语文书;英语书
+005,小强,三班,英语书
+`
+	report, err := service.Import(strings.NewReader(roster2), abstract.DryRun())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("试运行: 需要 %d 本，缺货 %v\n", report.Requested, report.Shortages)
+	if _, err := service.Import(strings.NewReader(roster2)); err != nil {
+		fmt.Println("没有发书:", err)
+	}
+	_ = stock.Add("语文书", 1)
+	_ = stock.Add("英语书", 2)
+	if report, err = service.Import(strings.NewReader(roster2)); err == nil {
+		fmt.Printf("补货后发出 %d 本，新学生 %d 人\n", report.Issued, report.NewStudents)
+	}
+
+	ledger := service.Ledger()
+	_ = ledger.WriteCSV(os.Stdout)
+	_ = ledger.WriteSummaryCSV(os.Stdout)
+	_ = ledger.WriteJSON(os.Stdout)
+	fmt.Println("剩余语文书:", stock.Count("语文书"))
+}
@@ -0,0 +1,105 @@
+package abstract
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+/**
+台账导出
+明细导出为 CSV 或 JSON，并按班级、科目汇总发放数量
+*/
+
+// Total 某个班级或科目的发放数量
+type Total struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// Summary 台账汇总，按名字排序
+type Summary struct {
+	ByClass   []Total `json:"by_class"`
+	BySubject []Total `json:"by_subject"`
+	Total     int     `json:"total"`
+}
+
+// Summary 按班级和科目汇总
+func (l *Ledger) Summary() Summary {
+	return summarize(l.Entries())
+}
+
+func summarize(entries []Entry) Summary {
+	byClass := make(map[string]int)
+	bySubject := make(map[string]int)
+	for _, e := range entries {
+		byClass[e.Class]++
+		bySubject[e.Subject]++
+	}
+	return Summary{
+		ByClass:   totals(byClass),
+		BySubject: totals(bySubject),
+		Total:     len(entries),
+	}
+}
+
+func totals(m map[string]int) []Total {
+	list := make([]Total, 0, len(m))
+	for key, count := range m {
+		list = append(list, Total{Key: key, Count: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
+
+var csvHeader = []string{"time", "student_id", "student", "class", "subject", "title"}
+
+// WriteCSV 以 CSV 导出明细，第一行为表头
+func (l *Ledger) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range l.Entries() {
+		record := []string{e.Time.Format(time.RFC3339), e.StudentID, e.Student, e.Class, e.Subject, e.Title}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteSummaryCSV 以 CSV 导出汇总，每行为 范围,名字,数量，范围为 class、subject 或 total
+func (l *Ledger) WriteSummaryCSV(w io.Writer) error {
+	summary := l.Summary()
+	cw := csv.NewWriter(w)
+	records := [][]string{{"scope", "key", "count"}}
+	for _, t := range summary.ByClass {
+		records = append(records, []string{"class", t.Key, strconv.Itoa(t.Count)})
+	}
+	for _, t := range summary.BySubject {
+		records = append(records, []string{"subject", t.Key, strconv.Itoa(t.Count)})
+	}
+	records = append(records, []string{"total", "", strconv.Itoa(summary.Total)})
+	return cw.WriteAll(records)
+}
+
+// WriteJSON 以 JSON 导出明细和汇总
+func (l *Ledger) WriteJSON(w io.Writer) error {
+	entries := l.Entries()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Entries []Entry `json:"entries"`
+		Summary Summary `json:"summary"`
+	}{
+		Entries: append([]Entry{}, entries...),
+		Summary: summarize(entries),
+	})
+}
@@ -0,0 +1,83 @@
+package abstract
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newExportLedger() *Ledger {
+	l := NewLedger()
+	at := time.Date(2024, 9, 1, 8, 0, 0, 0, time.UTC)
+	for _, e := range []Entry{
+		{StudentID: "0001", Student: "张三", Class: "1班", Subject: "语文", Title: "语文书"},
+		{StudentID: "0001", Student: "张三", Class: "1班", Subject: "数学", Title: "数学书"},
+		{StudentID: "0002", Student: "李四", Class: "2班", Subject: "语文", Title: "语文书"},
+	} {
+		e.Time = at
+		l.record(e)
+	}
+	return l
+}
+
+func TestSummary(t *testing.T) {
+	want := Summary{
+		ByClass:   []Total{{"1班", 2}, {"2班", 1}},
+		BySubject: []Total{{"数学", 1}, {"语文", 2}},
+		Total:     3,
+	}
+	if got := newExportLedger().Summary(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Summary = %+v, want %+v", got, want)
+	}
+	if got := NewLedger().Summary(); got.Total != 0 || len(got.ByClass) != 0 {
+		t.Fatalf("empty Summary = %+v", got)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	l := newExportLedger()
+	var buf bytes.Buffer
+	if err := l.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || !reflect.DeepEqual(records[0], csvHeader) {
+		t.Fatalf("records = %v", records)
+	}
+	if want := []string{"2024-09-01T08:00:00Z", "0001", "张三", "1班", "数学", "数学书"}; !reflect.DeepEqual(records[2], want) {
+		t.Fatalf("row 2 = %v, want %v", records[2], want)
+	}
+
+	buf.Reset()
+	if err := l.WriteSummaryCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "scope,key,count\nclass,1班,2\nclass,2班,1\nsubject,数学,1\nsubject,语文,2\ntotal,,3\n"
+	if buf.String() != want {
+		t.Fatalf("summary CSV =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	l := newExportLedger()
+	var buf bytes.Buffer
+	if err := l.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Entries []Entry `json:"entries"`
+		Summary Summary `json:"summary"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Entries, l.Entries()) || !reflect.DeepEqual(got.Summary, l.Summary()) {
+		t.Fatalf("JSON = %s", buf.String())
+	}
+}
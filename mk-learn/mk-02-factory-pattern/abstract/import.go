@@ -0,0 +1,230 @@
+package abstract
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+/**
+花名册导入
+CSV 的表头为 id,name,class,titles，titles 为要发的书，多本用分号分隔。
+先按库存核对，库存不够时不发任何一本；试运行只核对不发放
+*/
+
+const importHoldTTL = time.Minute
+
+// ImportOption 设置导入参数
+type ImportOption func(cfg *importConfig)
+
+type importConfig struct {
+	dryRun bool
+}
+
+// DryRun 只核对库存，不登记学生也不发书
+func DryRun() ImportOption {
+	return func(cfg *importConfig) {
+		cfg.dryRun = true
+	}
+}
+
+// Shortage 某本书库存不足
+type Shortage struct {
+	Title string `json:"title"`
+	Need  int    `json:"need"`
+	Have  int    `json:"have"`
+}
+
+// ImportReport 导入结果
+type ImportReport struct {
+	DryRun      bool       `json:"dry_run"`
+	Students    int        `json:"students"`     // CSV 中的学生数
+	NewStudents int        `json:"new_students"` // 花名册中没有的学生数
+	Requested   int        `json:"requested"`    // 需要发的书，不含已经领过的
+	Skipped     int        `json:"skipped"`      // 已经领过的
+	Issued      int        `json:"issued"`       // 实际发出的
+	Shortages   []Shortage `json:"shortages,omitempty"`
+}
+
+// importRow CSV 中的一行
+type importRow struct {
+	line    int
+	student Student
+	titles  []string
+}
+
+// Import 按 CSV 批量发书。库存不足时返回报告和 ErrOutOfStock，不发任何一本；
+// 试运行时只返回报告
+func (s *Service) Import(r io.Reader, opts ...ImportOption) (*ImportReport, error) {
+	cfg := &importConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	rows, err := s.parseImport(r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: cfg.dryRun, Students: len(rows)}
+	var newStudents []Student
+	need := make(map[string]int)
+	for _, row := range rows {
+		if _, err := s.roster.Get(row.student.ID); errors.Is(err, ErrUnknownStudent) {
+			newStudents = append(newStudents, row.student)
+		}
+		for _, title := range row.titles {
+			if s.ledger.Issued(row.student.ID, title) {
+				report.Skipped++
+				continue
+			}
+			need[title]++
+			report.Requested++
+		}
+	}
+	report.NewStudents = len(newStudents)
+	report.Shortages = s.shortages(need)
+	if cfg.dryRun {
+		return report, nil
+	}
+	if len(report.Shortages) > 0 {
+		return report, fmt.Errorf("%w: %d titles short", ErrOutOfStock, len(report.Shortages))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type pending struct {
+		student Student
+		subject string
+		title   string
+		hold    *Hold
+	}
+	var holds []pending
+	for _, row := range rows {
+		for _, title := range row.titles {
+			if s.ledger.Issued(row.student.ID, title) {
+				continue
+			}
+			sa, _ := s.assigner.lookup(title) // 书名在 parseImport 中检查过
+			hold, err := sa.(reserver).tryReserveBook(title, importHoldTTL)
+			if err != nil {
+				// 核对之后库存被别人领走了，已经预留的全部放回
+				for _, p := range holds {
+					_ = p.hold.Release()
+				}
+				return report, err
+			}
+			holds = append(holds, pending{student: row.student, subject: sa.Subject(), title: title, hold: hold})
+		}
+	}
+	for i, student := range newStudents {
+		if err := s.roster.Add(student); err != nil {
+			// 核对之后学号被别人登记了，已经登记的学生和预留的书全部撤回
+			for _, added := range newStudents[:i] {
+				s.roster.remove(added.ID)
+			}
+			for _, p := range holds {
+				_ = p.hold.Release()
+			}
+			return report, err
+		}
+	}
+	for _, p := range holds {
+		if err := p.hold.Confirm(); err != nil {
+			return report, err
+		}
+		s.record(p.student, p.subject, p.title)
+		report.Issued++
+	}
+	return report, nil
+}
+
+// parseImport 读取 CSV 并检查学生和书名，同一个学生重复的书只发一次
+func (s *Service) parseImport(r io.Reader) ([]importRow, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("assigner: read import header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"id", "name", "class", "titles"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("assigner: import header missing %q", name)
+		}
+	}
+
+	var rows []importRow
+	seen := make(map[string]int) // 学号 -> 行号
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("assigner: import: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		row := importRow{
+			line: line,
+			student: Student{
+				ID:    strings.TrimSpace(record[cols["id"]]),
+				Name:  strings.TrimSpace(record[cols["name"]]),
+				Class: strings.TrimSpace(record[cols["class"]]),
+			},
+		}
+		if err := s.checkImportRow(&row, record[cols["titles"]]); err != nil {
+			return nil, err
+		}
+		if prev, ok := seen[row.student.ID]; ok {
+			return nil, fmt.Errorf("assigner: import line %d: student %q already on line %d", line, row.student.ID, prev)
+		}
+		seen[row.student.ID] = line
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (s *Service) checkImportRow(row *importRow, titles string) error {
+	if row.student.ID == "" {
+		return fmt.Errorf("assigner: import line %d: empty student id", row.line)
+	}
+	if known, err := s.roster.Get(row.student.ID); err == nil && known != row.student {
+		return fmt.Errorf("assigner: import line %d: student %q does not match roster", row.line, row.student.ID)
+	}
+	dup := make(map[string]bool)
+	for _, title := range strings.Split(titles, ";") {
+		title = strings.TrimSpace(title)
+		if title == "" || dup[title] {
+			continue
+		}
+		r, ok := s.reserverOf(title)
+		if !ok || !r.hasBook(title) {
+			return fmt.Errorf("assigner: import line %d: %w: %q", row.line, ErrUnknownTitle, title)
+		}
+		dup[title] = true
+		row.titles = append(row.titles, title)
+	}
+	return nil
+}
+
+// shortages 按书名排序的缺货清单
+func (s *Service) shortages(need map[string]int) []Shortage {
+	var list []Shortage
+	for title, n := range need {
+		r, _ := s.reserverOf(title)
+		if have := r.available(title); have < n {
+			list = append(list, Shortage{Title: title, Need: n, Have: have})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Title < list[j].Title
+	})
+	return list
+}
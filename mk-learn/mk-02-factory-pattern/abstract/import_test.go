@@ -0,0 +1,148 @@
+package abstract
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newImportService 语文书 chinese 本、数学书 math 本，花名册里有 0001 和 0002
+func newImportService(t *testing.T, chinese, math int) (*Service, *Stock) {
+	t.Helper()
+	stock := NewStock()
+	for title, n := range map[string]int{"语文书": chinese, "数学书": math} {
+		if n > 0 {
+			if err := stock.Add(title, n); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	roster, err := NewRoster(
+		Student{ID: "0001", Name: "张三", Class: "1班"},
+		Student{ID: "0002", Name: "李四", Class: "2班"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := NewService(roster, NewChineseAssigner(stock), NewMathAssigner(stock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service, stock
+}
+
+// importState 导入前后需要比较的状态
+type importState struct {
+	Counts   map[string]int
+	Held     map[string]int
+	Students []Student
+	Entries  int
+}
+
+func stateOf(s *Service, stock *Stock) importState {
+	st := importState{Counts: map[string]int{}, Held: map[string]int{}}
+	for _, title := range []string{"语文书", "数学书"} {
+		st.Counts[title] = stock.Count(title)
+		st.Held[title] = stock.Held(title)
+	}
+	st.Students = s.roster.Students()
+	st.Entries = s.ledger.Len()
+	return st
+}
+
+const importCSV = `id,name,class,titles
+0001,张三,1班,语文书;数学书
+0002,李四,2班,语文书; 数学书
+0003,王五,1班,语文书;数学书;语文书
+`
+
+func TestImportDryRun(t *testing.T) {
+	service, stock := newImportService(t, 2, 1)
+	before := stateOf(service, stock)
+
+	report, err := service.Import(strings.NewReader(importCSV), DryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ImportReport{
+		DryRun:      true,
+		Students:    3,
+		NewStudents: 1,
+		Requested:   6,
+		Shortages: []Shortage{
+			{Title: "数学书", Need: 3, Have: 1},
+			{Title: "语文书", Need: 3, Have: 2},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("report = %+v, want %+v", report, want)
+	}
+	if after := stateOf(service, stock); !reflect.DeepEqual(after, before) {
+		t.Fatalf("dry run changed state: %+v -> %+v", before, after)
+	}
+}
+
+func TestImportFailureLeavesStateUnchanged(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		wantErr error
+	}{
+		{"out of stock", importCSV, ErrOutOfStock},
+		{"unknown title", "id,name,class,titles\n0003,王五,1班,语文书\n0004,赵六,1班,物理书\n", ErrUnknownTitle},
+		{"roster mismatch", "id,name,class,titles\n0003,王五,1班,语文书\n0001,张三,3班,语文书\n", nil},
+		{"duplicate student", "id,name,class,titles\n0003,王五,1班,语文书\n0003,王五,1班,数学书\n", nil},
+		{"missing column", "id,name,titles\n0003,王五,语文书\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, stock := newImportService(t, 2, 1)
+			before := stateOf(service, stock)
+
+			report, err := service.Import(strings.NewReader(tt.csv))
+			if err == nil {
+				t.Fatalf("Import succeeded: %+v", report)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if report != nil && report.Issued != 0 {
+				t.Fatalf("issued %d books in a failed import", report.Issued)
+			}
+			if after := stateOf(service, stock); !reflect.DeepEqual(after, before) {
+				t.Fatalf("failed import changed state: %+v -> %+v", before, after)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	service, stock := newImportService(t, 3, 3)
+	if _, err := service.IssueBook("0001", "语文书"); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := service.Import(strings.NewReader(importCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Skipped != 1 || report.Requested != 5 || report.Issued != 5 || report.NewStudents != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	if _, err := service.roster.Get("0003"); err != nil {
+		t.Fatal(err)
+	}
+	if stock.Count("语文书") != 0 || stock.Count("数学书") != 0 || service.ledger.Len() != 6 {
+		t.Fatalf("stock %d/%d, ledger %d", stock.Count("语文书"), stock.Count("数学书"), service.ledger.Len())
+	}
+
+	// 再导入一次全部跳过
+	report, err = service.Import(strings.NewReader(importCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Skipped != 6 || report.Issued != 0 || report.NewStudents != 0 {
+		t.Fatalf("second report = %+v", report)
+	}
+}
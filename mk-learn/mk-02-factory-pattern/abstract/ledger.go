@@ -0,0 +1,62 @@
+package abstract
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry 一条发放记录
+type Entry struct {
+	Time      time.Time `json:"time"`
+	StudentID string    `json:"student_id"`
+	Student   string    `json:"student"`
+	Class     string    `json:"class"`
+	Subject   string    `json:"subject"`
+	Title     string    `json:"title"`
+}
+
+// Ledger 发放台账，按发放顺序记录
+type Ledger struct {
+	mu      sync.RWMutex
+	entries []Entry
+	issued  map[issueKey]bool
+}
+
+type issueKey struct {
+	student string
+	title   string
+}
+
+// NewLedger 创建空的台账
+func NewLedger() *Ledger {
+	return &Ledger{issued: make(map[issueKey]bool)}
+}
+
+// Issued 学生是否已经领过 title
+func (l *Ledger) Issued(studentID, title string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.issued[issueKey{studentID, title}]
+}
+
+// Entries 所有记录的副本
+func (l *Ledger) Entries() []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]Entry(nil), l.entries...)
+}
+
+// Len 记录数
+func (l *Ledger) Len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.entries)
+}
+
+// record 追加记录，调用方需要先检查没有重复发放
+func (l *Ledger) record(e Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+	l.issued[issueKey{e.StudentID, e.Title}] = true
+}
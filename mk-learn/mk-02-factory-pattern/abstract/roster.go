@@ -0,0 +1,80 @@
+package abstract
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Student 学生
+type Student struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Class string `json:"class"`
+}
+
+// Roster 花名册
+type Roster struct {
+	mu       sync.RWMutex
+	students map[string]Student
+}
+
+// NewRoster 创建花名册
+func NewRoster(students ...Student) (*Roster, error) {
+	r := &Roster{students: make(map[string]Student)}
+	for _, s := range students {
+		if err := r.Add(s); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Add 加入学生，学号为空或重复时返回错误
+func (r *Roster) Add(s Student) error {
+	if s.ID == "" {
+		return fmt.Errorf("assigner: empty student id for %q", s.Name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.students[s.ID]; ok {
+		return fmt.Errorf("assigner: duplicate student id %q", s.ID)
+	}
+	r.students[s.ID] = s
+	return nil
+}
+
+// remove 移除学生，导入失败时撤回已经登记的学生
+func (r *Roster) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.students, id)
+}
+
+// Get 按学号查找学生，找不到时返回 ErrUnknownStudent
+func (r *Roster) Get(id string) (Student, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.students[id]
+	if !ok {
+		return Student{}, fmt.Errorf("%w: %q", ErrUnknownStudent, id)
+	}
+	return s, nil
+}
+
+// Students 所有学生，按班级和学号排序
+func (r *Roster) Students() []Student {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := make([]Student, 0, len(r.students))
+	for _, s := range r.students {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Class != list[j].Class {
+			return list[i].Class < list[j].Class
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
@@ -0,0 +1,197 @@
+package abstract
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+/**
+教材发放
+学生凭学号领书，同一本书每人只能领一次，每次发放都记到台账上
+*/
+
+// Service 发书服务
+type Service struct {
+	roster   *Roster
+	ledger   *Ledger
+	assigner *assigner
+
+	mu sync.Mutex // 串行化检查重复发放和记账
+}
+
+// NewService 创建发书服务，书名重复时返回错误
+func NewService(roster *Roster, subjects ...SubjectAssigner) (*Service, error) {
+	a, err := newAssigner(subjects)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		roster:   roster,
+		ledger:   NewLedger(),
+		assigner: a,
+	}, nil
+}
+
+// Assigner 按书名分发的发书人，不检查花名册，也不记账
+func (s *Service) Assigner() Assigner {
+	return s.assigner
+}
+
+// Ledger 发放台账
+func (s *Service) Ledger() *Ledger {
+	return s.ledger
+}
+
+// IssueBook 给学生发一本书
+func (s *Service) IssueBook(studentID, title string) (Book, error) {
+	var book Book
+	err := s.issue(studentID, title, func() (err error) {
+		book, err = s.assigner.GetBook(title)
+		return err
+	})
+	return book, err
+}
+
+// IssuePaper 给学生发一份试卷，配置了考试的科目按学号组卷，每个学生一份
+func (s *Service) IssuePaper(studentID, title string) (Paper, error) {
+	var paper Paper
+	err := s.issue(studentID, title, func() (err error) {
+		sa, err := s.assigner.lookup(title)
+		if err != nil {
+			return err
+		}
+		if e, ok := sa.(examiner); ok {
+			var generated bool
+			if paper, generated, err = e.paperFor(title, studentID); generated {
+				return err
+			}
+		}
+		paper, err = s.assigner.GetPaper(title)
+		return err
+	})
+	return paper, err
+}
+
+// issue 检查学生和重复发放，take 从发书人那里取出书，成功后记账
+func (s *Service) issue(studentID, title string, take func() error) error {
+	student, subject, err := s.check(studentID, title)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ledger.Issued(studentID, title) {
+		return fmt.Errorf("%w: %q to %s", ErrAlreadyIssued, title, studentID)
+	}
+	if err := take(); err != nil {
+		return err
+	}
+	s.record(student, subject, title)
+	return nil
+}
+
+// check 检查学生和书名，返回学生和书所属的科目
+func (s *Service) check(studentID, title string) (Student, string, error) {
+	student, err := s.roster.Get(studentID)
+	if err != nil {
+		return Student{}, "", err
+	}
+	sa, err := s.assigner.lookup(title)
+	if err != nil {
+		return Student{}, "", err
+	}
+	return student, sa.Subject(), nil
+}
+
+// reserverOf 书名对应的支持预留的发书人
+func (s *Service) reserverOf(title string) (reserver, bool) {
+	sa, err := s.assigner.lookup(title)
+	if err != nil {
+		return nil, false
+	}
+	r, ok := sa.(reserver)
+	return r, ok
+}
+
+// record 记账，调用方需要持有 s.mu
+func (s *Service) record(student Student, subject, title string) {
+	s.ledger.record(Entry{
+		Time:      time.Now(),
+		StudentID: student.ID,
+		Student:   student.Name,
+		Class:     student.Class,
+		Subject:   subject,
+		Title:     title,
+	})
+}
+
+// ReserveBook 给学生预留一本书，没有库存时排队等补货，ctx 取消时放弃排队。
+// 预留在 ttl 内没有确认会自动放回库存
+func (s *Service) ReserveBook(ctx context.Context, studentID, title string, ttl time.Duration) (*Reservation, error) {
+	student, subject, err := s.check(studentID, title)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := s.reserverOf(title)
+	if !ok {
+		return nil, fmt.Errorf("assigner: %q does not support reservations", title)
+	}
+	if s.ledger.Issued(studentID, title) {
+		return nil, fmt.Errorf("%w: %q to %s", ErrAlreadyIssued, title, studentID)
+	}
+	hold, err := r.reserveBook(ctx, title, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &Reservation{
+		svc:     s,
+		maker:   r,
+		student: student,
+		subject: subject,
+		hold:    hold,
+	}, nil
+}
+
+// Reservation 给某个学生预留的一本书
+type Reservation struct {
+	svc     *Service
+	maker   reserver
+	student Student
+	subject string
+	hold    *Hold
+}
+
+// Title 预留的书名
+func (r *Reservation) Title() string {
+	return r.hold.Title
+}
+
+// Expires 预留的截止时间
+func (r *Reservation) Expires() time.Time {
+	return r.hold.Expires
+}
+
+// Confirm 确认领书并记账；已经超时返回 ErrHoldExpired，
+// 学生在预留期间已经领过这本书时放回预留并返回 ErrAlreadyIssued
+func (r *Reservation) Confirm() (Book, error) {
+	s := r.svc
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ledger.Issued(r.student.ID, r.hold.Title) {
+		_ = r.hold.Release()
+		return nil, fmt.Errorf("%w: %q to %s", ErrAlreadyIssued, r.hold.Title, r.student.ID)
+	}
+	if err := r.hold.Confirm(); err != nil {
+		return nil, err
+	}
+	s.record(r.student, r.subject, r.hold.Title)
+	return r.maker.bookOf(r.hold.Title), nil
+}
+
+// Cancel 放弃预留，书放回库存
+func (r *Reservation) Cancel() error {
+	return r.hold.Release()
+}
@@ -0,0 +1,217 @@
+package abstract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+/**
+库存
+整个班同时领书时，扣减库存在锁内完成；
+预留的书超时没有确认自动放回库存，库存不足时排队，补货后按先来后到分配
+*/
+
+var (
+	ErrHoldExpired  = errors.New("assigner: hold expired")
+	ErrHoldReleased = errors.New("assigner: hold already confirmed or released")
+)
+
+// Stock 每个书名的库存数量
+type Stock struct {
+	mu      sync.Mutex
+	counts  map[string]int
+	held    map[string]int
+	waiters map[string][]*waiter // 每个书名的等待队列，先进先出
+}
+
+// waiter 等待补货的预留请求
+type waiter struct {
+	ttl time.Duration
+	ch  chan *Hold // 容量为 1，分配后不会阻塞
+}
+
+// NewStock 创建空的库存
+func NewStock() *Stock {
+	return &Stock{
+		counts:  make(map[string]int),
+		held:    make(map[string]int),
+		waiters: make(map[string][]*waiter),
+	}
+}
+
+// Add 增加库存，n 必须大于 0；有人排队时先分配给排队的人
+func (s *Stock) Add(title string, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("assigner: invalid count %d for %q", n, title)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[title] += n
+	s.serve(title)
+	return nil
+}
+
+// Take 取出一本，没有库存时返回 ErrOutOfStock
+func (s *Stock) Take(title string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts[title] <= 0 {
+		return fmt.Errorf("%w: %q", ErrOutOfStock, title)
+	}
+	s.counts[title]--
+	return nil
+}
+
+// Count 当前可用的库存数量，不包括预留的
+func (s *Stock) Count(title string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counts[title]
+}
+
+// Held 已预留还没有确认的数量
+func (s *Stock) Held(title string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.held[title]
+}
+
+// Backorders 排队等待补货的数量
+func (s *Stock) Backorders(title string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.waiters[title])
+}
+
+// TryReserve 预留一本，ttl 内没有确认自动放回库存；没有库存时返回 ErrOutOfStock
+func (s *Stock) TryReserve(title string, ttl time.Duration) (*Hold, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counts[title] <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrOutOfStock, title)
+	}
+	return s.hold(title, ttl), nil
+}
+
+// Reserve 预留一本，没有库存时排队等待补货，ctx 取消时返回 ctx.Err()。
+// ttl 从分配到预留时开始计算
+func (s *Stock) Reserve(ctx context.Context, title string, ttl time.Duration) (*Hold, error) {
+	s.mu.Lock()
+	if s.counts[title] > 0 {
+		h := s.hold(title, ttl)
+		s.mu.Unlock()
+		return h, nil
+	}
+	w := &waiter{ttl: ttl, ch: make(chan *Hold, 1)}
+	s.waiters[title] = append(s.waiters[title], w)
+	s.mu.Unlock()
+
+	select {
+	case h := <-w.ch:
+		return h, nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	removed := s.removeWaiter(title, w)
+	s.mu.Unlock()
+	if !removed {
+		// 取消的同时已经分配到了，放回去给后面排队的人
+		_ = (<-w.ch).Release()
+	}
+	return nil, ctx.Err()
+}
+
+// hold 从可用库存中预留一本，调用方需要持有 s.mu 并确认有库存
+func (s *Stock) hold(title string, ttl time.Duration) *Hold {
+	s.counts[title]--
+	s.held[title]++
+	h := &Hold{Title: title, Expires: time.Now().Add(ttl), stock: s}
+	h.timer = time.AfterFunc(ttl, h.expire)
+	return h
+}
+
+// serve 按排队顺序分配库存，调用方需要持有 s.mu
+func (s *Stock) serve(title string) {
+	queue := s.waiters[title]
+	for len(queue) > 0 && s.counts[title] > 0 {
+		w := queue[0]
+		queue = queue[1:]
+		w.ch <- s.hold(title, w.ttl)
+	}
+	if len(queue) == 0 {
+		delete(s.waiters, title)
+	} else {
+		s.waiters[title] = queue
+	}
+}
+
+// removeWaiter 把 w 移出队列，w 已经分配到时返回 false，调用方需要持有 s.mu
+func (s *Stock) removeWaiter(title string, w *waiter) bool {
+	queue := s.waiters[title]
+	for i, q := range queue {
+		if q == w {
+			s.waiters[title] = append(queue[:i:i], queue[i+1:]...)
+			if len(s.waiters[title]) == 0 {
+				delete(s.waiters, title)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Hold 预留的一本书
+type Hold struct {
+	Title   string
+	Expires time.Time
+
+	stock *Stock
+	timer *time.Timer
+	state error // nil 表示预留中，由 stock.mu 保护
+}
+
+// Confirm 确认预留，书从库存中取走；已经超时返回 ErrHoldExpired
+func (h *Hold) Confirm() error {
+	s := h.stock
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h.state != nil {
+		return h.err()
+	}
+	h.timer.Stop()
+	h.state = ErrHoldReleased
+	s.held[h.Title]--
+	return nil
+}
+
+// Release 放弃预留，书放回库存
+func (h *Hold) Release() error {
+	return h.giveBack(ErrHoldReleased)
+}
+
+func (h *Hold) expire() {
+	_ = h.giveBack(ErrHoldExpired)
+}
+
+func (h *Hold) giveBack(state error) error {
+	s := h.stock
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h.state != nil {
+		return h.err()
+	}
+	h.timer.Stop()
+	h.state = state
+	s.held[h.Title]--
+	s.counts[h.Title]++
+	s.serve(h.Title)
+	return nil
+}
+
+func (h *Hold) err() error {
+	return fmt.Errorf("%w: %q", h.state, h.Title)
+}
@@ -0,0 +1,200 @@
+package abstract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T, students, copies int) (*Service, *Stock, []Student) {
+	t.Helper()
+	stock := NewStock()
+	if copies > 0 {
+		if err := stock.Add("语文书", copies); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list := make([]Student, students)
+	for i := range list {
+		list[i] = Student{
+			ID:    fmt.Sprintf("%04d", i+1),
+			Name:  fmt.Sprintf("学生%d", i+1),
+			Class: fmt.Sprintf("%d班", i%4+1),
+		}
+	}
+	roster, err := NewRoster(list...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := NewService(roster, NewChineseAssigner(stock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service, stock, list
+}
+
+// TestStockConcurrent 用 go test -race 运行，检查并发领书时库存数量守恒
+func TestStockConcurrent(t *testing.T) {
+	t.Run("no overselling", func(t *testing.T) {
+		const students, copies = 200, 120
+		service, stock, list := newTestService(t, students, copies)
+
+		// 每个学生同时领两次，每人最多领到一本，总数不超过库存
+		var issued, outOfStock, duplicate int64
+		var wg sync.WaitGroup
+		for _, student := range list {
+			for i := 0; i < 2; i++ {
+				wg.Add(1)
+				go func(id string) {
+					defer wg.Done()
+					_, err := service.IssueBook(id, "语文书")
+					switch {
+					case err == nil:
+						atomic.AddInt64(&issued, 1)
+					case errors.Is(err, ErrOutOfStock):
+						atomic.AddInt64(&outOfStock, 1)
+					case errors.Is(err, ErrAlreadyIssued):
+						atomic.AddInt64(&duplicate, 1)
+					default:
+						t.Error(err)
+					}
+				}(student.ID)
+			}
+		}
+		wg.Wait()
+
+		if issued != copies {
+			t.Fatalf("issued %d, want %d", issued, copies)
+		}
+		if issued+outOfStock+duplicate != 2*students {
+			t.Fatalf("issued %d + out of stock %d + duplicate %d != %d", issued, outOfStock, duplicate, 2*students)
+		}
+		if n := service.Ledger().Len(); n != copies {
+			t.Fatalf("ledger has %d entries, want %d", n, copies)
+		}
+		if n := stock.Count("语文书"); n != 0 {
+			t.Fatalf("stock left %d, want 0", n)
+		}
+	})
+
+	t.Run("expired holds return to stock", func(t *testing.T) {
+		const students, copies = 100, 60
+		const ttl = 20 * time.Millisecond
+		service, stock, list := newTestService(t, students, copies)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		// 一半的预留马上确认，另一半等到超时再确认
+		var confirmed, expired int64
+		var wg sync.WaitGroup
+		for i, student := range list {
+			wg.Add(1)
+			go func(i int, id string) {
+				defer wg.Done()
+				r, err := service.ReserveBook(ctx, id, "语文书", ttl)
+				if err != nil {
+					return // 排队到 ctx 超时
+				}
+				if i%2 == 1 {
+					time.Sleep(2 * ttl)
+				}
+				_, err = r.Confirm()
+				switch {
+				case err == nil:
+					atomic.AddInt64(&confirmed, 1)
+				case errors.Is(err, ErrHoldExpired):
+					atomic.AddInt64(&expired, 1)
+				default:
+					t.Error(err)
+				}
+			}(i, student.ID)
+		}
+		wg.Wait()
+
+		if expired == 0 {
+			t.Fatal("no hold expired")
+		}
+		if n := stock.Held("语文书"); n != 0 {
+			t.Fatalf("%d holds left", n)
+		}
+		if n := stock.Count("语文书"); n != copies-int(confirmed) {
+			t.Fatalf("stock left %d, want %d", n, copies-int(confirmed))
+		}
+		if n := service.Ledger().Len(); n != int(confirmed) {
+			t.Fatalf("ledger has %d entries, want %d", n, confirmed)
+		}
+	})
+
+	t.Run("backorders are served in order", func(t *testing.T) {
+		const students = 50
+		service, stock, list := newTestService(t, students, 0)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		served := make(chan string, students)
+		var wg sync.WaitGroup
+		for i, student := range list {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+				r, err := service.ReserveBook(ctx, id, "语文书", time.Minute)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				served <- id
+				if _, err := r.Confirm(); err != nil {
+					t.Error(err)
+				}
+			}(student.ID)
+			// 等上一个人排上队，保证排队顺序和学号顺序一致
+			for stock.Backorders("语文书") != i+1 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+
+		for i := range list {
+			if err := stock.Add("语文书", 1); err != nil {
+				t.Fatal(err)
+			}
+			if id := <-served; id != list[i].ID {
+				t.Fatalf("served %s at %d, want %s", id, i, list[i].ID)
+			}
+		}
+		wg.Wait()
+
+		if n := stock.Count("语文书") + stock.Held("语文书") + stock.Backorders("语文书"); n != 0 {
+			t.Fatalf("stock not drained: %d", n)
+		}
+		if n := service.Ledger().Len(); n != students {
+			t.Fatalf("ledger has %d entries, want %d", n, students)
+		}
+	})
+
+	t.Run("cancelled backorder leaves the queue", func(t *testing.T) {
+		stock := NewStock()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			_, err := stock.Reserve(ctx, "语文书", time.Minute)
+			done <- err
+		}()
+		for stock.Backorders("语文书") != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Fatalf("err = %v, want context.Canceled", err)
+		}
+		if err := stock.Add("语文书", 1); err != nil {
+			t.Fatal(err)
+		}
+		if stock.Count("语文书") != 1 || stock.Backorders("语文书") != 0 {
+			t.Fatalf("count %d, backorders %d", stock.Count("语文书"), stock.Backorders("语文书"))
+		}
+	})
+}
@@ -1,6 +1,11 @@
 package main
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"design-pattern-go/book-learn/p2-factory-pattern/registry"
+)
 
 /**
 简单工厂
@@ -49,19 +54,30 @@ func (eb *englishBook) Name() string {
 	return eb.name
 }
 
-func GetBook(name string) Book {
-	if name == "语文书" {
-		return &chineseBook{name: "语文书"}
-	} else if name == "数学书" {
-		return &mathBook{name: "数学书"}
-	} else if name == "英语书" {
-		return &englishBook{name: "英语书"}
-	}
-	return nil
+var ErrUnknownTitle = errors.New("book: unknown title")
+
+var books = registry.NewRegistry[string, Book]("book", registry.WithErrors(ErrUnknownTitle, nil))
+
+func init() {
+	books.MustRegister("语文书", registry.NoArgs(func() Book { return &chineseBook{name: "语文书"} }))
+	books.MustRegister("数学书", registry.NoArgs(func() Book { return &mathBook{name: "数学书"} }))
+	books.MustRegister("英语书", registry.NoArgs(func() Book { return &englishBook{name: "英语书"} }))
+}
+
+// GetBook 按书名发书，书名不存在时返回错误
+func GetBook(name string) (Book, error) {
+	return books.New(name)
 }
 
 func main() {
 	//这边暴露了ChineseBook，如果在实际开发中，一个结构体可能包含dbClient，redisClient
 	//cb := &chineseBook{name: "语文书"}
-	fmt.Println(GetBook("语文书").Name())
+	for _, name := range []string{"语文书", "物理书"} {
+		book, err := GetBook(name)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(book.Name())
+	}
 }